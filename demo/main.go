@@ -1,13 +1,18 @@
 package main
 
 import (
+	"embed"
 	"fmt"
+	"os"
 	"strconv"
 
 	"github.com/ramansharma100/express-go/demo/routes"
 	"github.com/ramansharma100/express-go/http"
 )
 
+//go:embed static templates
+var assets embed.FS
+
 func middlewareTest1(ctx *http.Context, next func()) {
 	fmt.Println("Middleware Group Test 1")
 	next()
@@ -21,7 +26,13 @@ func middlewareTest2(ctx *http.Context, next func()) {
 func main() {
 	app := http.New()
 
-	app.Static("/static", "/demo/static")
+	// dev mode reads static files and templates from disk, production uses the embedded copies
+	app.SetServerOptions(&http.ServerOptions{
+		Dev: os.Getenv("APP_ENV") != "production",
+	})
+
+	app.SetViews(&http.ViewOptions{FS: assets, Dir: "templates"})
+	app.Static("/static", "static", &http.StaticOptions{FS: assets})
 
 	app.Use(func(ctx *http.Context, next func()) {
 		fmt.Println("Middleware Global 1")
@@ -49,13 +60,17 @@ func main() {
 		})
 	})
 
+	// template locals shared by every render
+	app.Locals["appName"] = "Express GO"
+
+	app.Use(func(ctx *http.Context, next func()) {
+		ctx.Locals["user"] = "Raman Sharma"
+		next()
+	})
+
 	app.Get("/", func(ctx *http.Context) {
-		ctx.Request.AddField("user", "Raman Sharma")
 		http.Logger().Info("Request received for / endpoint")
-		// ctx.Response.Send("Hello, World!" + ctx.Request.AdditionalFields["user"].(string))
-		ctx.Render("index.html", map[string]any{
-			"user": ctx.Request.AdditionalFields["user"],
-		})
+		ctx.Render("index.html", nil)
 	}).Name("home")
 
 	app.Use(func(ctx *http.Context, next func()) {
@@ -0,0 +1,368 @@
+// Package expresstest sends requests to an application in-process, without
+// Listen or a network socket, and asserts on the responses.
+//
+//	client := expresstest.New(t, app)
+//	client.Get("/users/1").Header("Authorization", "Bearer token").
+//		Expect(200).
+//		ExpectJSON(map[string]any{"id": 1})
+package expresstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// TB is the part of testing.TB the client reports failures to.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Client keeps cookies between requests so session flows can be tested.
+type Client struct {
+	SnapshotDir string
+
+	t       TB
+	handler http.Handler
+	jar     http.CookieJar
+	baseURL *url.URL
+	headers http.Header
+}
+
+func New(t TB, handler http.Handler) *Client {
+	jar, _ := cookiejar.New(nil)
+	baseURL, _ := url.Parse("http://example.com")
+	return &Client{
+		SnapshotDir: filepath.Join("testdata", "snapshots"),
+		t:           t,
+		handler:     handler,
+		jar:         jar,
+		baseURL:     baseURL,
+		headers:     make(http.Header),
+	}
+}
+
+// SetHeader sets a header sent with every request of the client.
+func (c *Client) SetHeader(key string, value string) *Client {
+	c.headers.Set(key, value)
+	return c
+}
+
+func (c *Client) Cookies() []*http.Cookie {
+	return c.jar.Cookies(c.baseURL)
+}
+
+func (c *Client) ClearCookies() {
+	c.jar, _ = cookiejar.New(nil)
+}
+
+func (c *Client) Get(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+func (c *Client) Post(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+func (c *Client) Put(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+func (c *Client) Patch(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+func (c *Client) Delete(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+func (c *Client) Head(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+func (c *Client) Options(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+func (c *Client) Request(method string, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: c.headers.Clone(),
+		query:  url.Values{},
+	}
+}
+
+// Request is built fluently and sent on the first Expect call (or Do).
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   []byte
+
+	fields []multipartField
+	files  []multipartFile
+
+	response *Response
+	err      error
+}
+
+type multipartField struct {
+	name  string
+	value string
+}
+
+type multipartFile struct {
+	field    string
+	filename string
+	content  []byte
+}
+
+func (r *Request) Header(key string, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+func (r *Request) Query(key string, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+func (r *Request) Cookie(name string, value string) *Request {
+	r.header.Add("Cookie", (&http.Cookie{Name: name, Value: value}).String())
+	return r
+}
+
+func (r *Request) Body(body string) *Request {
+	r.body = []byte(body)
+	return r
+}
+
+func (r *Request) JSON(body any) *Request {
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.body = data
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+func (r *Request) Form(values url.Values) *Request {
+	r.body = []byte(values.Encode())
+	r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// Field adds a multipart form field; the body is sent as multipart/form-data
+// once a Field or File is added.
+func (r *Request) Field(name string, value string) *Request {
+	r.fields = append(r.fields, multipartField{name: name, value: value})
+	return r
+}
+
+func (r *Request) File(field string, filename string, content []byte) *Request {
+	r.files = append(r.files, multipartFile{field: field, filename: filename, content: content})
+	return r
+}
+
+// Do sends the request once and returns the recorded response.
+func (r *Request) Do() *Response {
+	if r.response != nil {
+		return r.response
+	}
+	r.client.t.Helper()
+
+	if r.err != nil {
+		r.client.t.Errorf("%s %s: %v", r.method, r.path, r.err)
+		r.response = &Response{Header: http.Header{}}
+		return r.response
+	}
+
+	body := r.body
+	if len(r.fields) > 0 || len(r.files) > 0 {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, field := range r.fields {
+			writer.WriteField(field.name, field.value)
+		}
+		for _, file := range r.files {
+			part, _ := writer.CreateFormFile(file.field, file.filename)
+			part.Write(file.content)
+		}
+		writer.Close()
+		body = buf.Bytes()
+		r.header.Set("Content-Type", writer.FormDataContentType())
+	}
+
+	target := r.path
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		r.client.t.Errorf("%s %s: %v", r.method, r.path, err)
+		r.response = &Response{Header: http.Header{}}
+		return r.response
+	}
+
+	// a relative target gives the handler the same URL a real server would
+	target = r.client.baseURL.ResolveReference(u).RequestURI()
+	absolute := r.client.baseURL.ResolveReference(u)
+
+	req := httptest.NewRequest(r.method, target, bytes.NewReader(body))
+	req.Header = r.header.Clone()
+	// an absolute path like "http://api.example.com/users" sets the Host header
+	req.Host = absolute.Host
+	for _, cookie := range r.client.jar.Cookies(absolute) {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(recorder, req)
+	result := recorder.Result()
+
+	r.client.jar.SetCookies(absolute, result.Cookies())
+
+	data, _ := io.ReadAll(result.Body)
+	r.response = &Response{
+		Status: result.StatusCode,
+		Header: result.Header,
+		Body:   data,
+	}
+	return r.response
+}
+
+func (r *Request) Response() *Response {
+	return r.Do()
+}
+
+func (r *Request) Expect(status int) *Request {
+	r.client.t.Helper()
+	res := r.Do()
+	if res.Status != status {
+		r.client.t.Errorf("%s %s: expected status %d, got %d\n%s", r.method, r.path, status, res.Status, res.Body)
+	}
+	return r
+}
+
+func (r *Request) ExpectHeader(key string, value string) *Request {
+	r.client.t.Helper()
+	if got := r.Do().Header.Get(key); got != value {
+		r.client.t.Errorf("%s %s: expected header %s to be %q, got %q", r.method, r.path, key, value, got)
+	}
+	return r
+}
+
+func (r *Request) ExpectBody(body string) *Request {
+	r.client.t.Helper()
+	if got := r.Do().Text(); got != body {
+		r.client.t.Errorf("%s %s: expected body %q, got %q", r.method, r.path, body, got)
+	}
+	return r
+}
+
+func (r *Request) ExpectBodyContains(substring string) *Request {
+	r.client.t.Helper()
+	if got := r.Do().Text(); !strings.Contains(got, substring) {
+		r.client.t.Errorf("%s %s: expected body to contain %q, got %q", r.method, r.path, substring, got)
+	}
+	return r
+}
+
+// ExpectJSON compares the body with expected after both are decoded into
+// generic JSON values, so key order and number types don't matter.
+func (r *Request) ExpectJSON(expected any) *Request {
+	r.client.t.Helper()
+	res := r.Do()
+
+	var got any
+	if err := json.Unmarshal(res.Body, &got); err != nil {
+		r.client.t.Errorf("%s %s: response is not JSON: %v\n%s", r.method, r.path, err, res.Body)
+		return r
+	}
+
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		r.client.t.Errorf("%s %s: cannot encode expected JSON: %v", r.method, r.path, err)
+		return r
+	}
+	var want any
+	json.Unmarshal(expectedJSON, &want)
+
+	if !reflect.DeepEqual(got, want) {
+		r.client.t.Errorf("%s %s: expected JSON %s, got %s", r.method, r.path, expectedJSON, res.Body)
+	}
+	return r
+}
+
+// ExpectSnapshot compares the status and body with SnapshotDir/name.snap. The
+// file is written when missing or when UPDATE_SNAPSHOTS is set.
+func (r *Request) ExpectSnapshot(name string) *Request {
+	r.client.t.Helper()
+	res := r.Do()
+
+	snapshot := res.snapshot()
+	file := filepath.Join(r.client.SnapshotDir, name+".snap")
+
+	existing, err := os.ReadFile(file)
+	if err != nil || os.Getenv("UPDATE_SNAPSHOTS") != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			r.client.t.Errorf("writing snapshot %s: %v", file, err)
+			return r
+		}
+		if err := os.WriteFile(file, []byte(snapshot), 0o644); err != nil {
+			r.client.t.Errorf("writing snapshot %s: %v", file, err)
+		}
+		return r
+	}
+
+	if string(existing) != snapshot {
+		r.client.t.Errorf("%s %s: response does not match snapshot %s (set UPDATE_SNAPSHOTS=1 to update)\nexpected:\n%s\ngot:\n%s", r.method, r.path, file, existing, snapshot)
+	}
+	return r
+}
+
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+func (res *Response) Text() string {
+	return string(res.Body)
+}
+
+func (res *Response) JSON(v any) error {
+	return json.Unmarshal(res.Body, v)
+}
+
+// snapshot pretty-prints JSON bodies so snapshot diffs stay readable.
+func (res *Response) snapshot() string {
+	body := res.Body
+	var indented bytes.Buffer
+	if json.Indent(&indented, res.Body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	return fmt.Sprintf("%d\n\n%s\n", res.Status, bytes.TrimRight(body, "\n"))
+}
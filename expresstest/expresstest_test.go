@@ -0,0 +1,160 @@
+package expresstest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+)
+
+// recorder collects the failures the client reports instead of failing the test.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// echo answers with a JSON description of the request it received.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	cookies := []string{}
+	for _, cookie := range r.Cookies() {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	if r.URL.Path == "/login" {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"method":      r.Method,
+		"host":        r.Host,
+		"uri":         r.RequestURI,
+		"token":       r.Header.Get("X-Token"),
+		"contentType": r.Header.Get("Content-Type"),
+		"cookies":     cookies,
+		"body":        string(body),
+	})
+})
+
+func decode(t *testing.T, res *expresstest.Response) map[string]any {
+	t.Helper()
+	var got map[string]any
+	if err := res.JSON(&got); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	return got
+}
+
+func TestRequestBuilding(t *testing.T) {
+	client := expresstest.New(t, echo).SetHeader("X-Token", "secret")
+
+	client.Post("/items?a=1").Query("b", "2").JSON(map[string]any{"name": "pen"}).
+		Expect(200).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJSON(map[string]any{
+			"method":      "POST",
+			"host":        "example.com",
+			"uri":         "/items?a=1&b=2",
+			"token":       "secret",
+			"contentType": "application/json",
+			"cookies":     []string{},
+			"body":        `{"name":"pen"}`,
+		})
+
+	got := decode(t, client.Put("/form").Form(url.Values{"q": {"a b"}}).Do())
+	if got["body"] != "q=a+b" || got["contentType"] != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected form request %v", got)
+	}
+
+	got = decode(t, client.Post("/upload").Field("title", "doc").File("file", "a.txt", []byte("content")).Do())
+	if !strings.HasPrefix(got["contentType"].(string), "multipart/form-data; boundary=") ||
+		!strings.Contains(got["body"].(string), `name="title"`) || !strings.Contains(got["body"].(string), "content") {
+		t.Errorf("unexpected multipart request %v", got)
+	}
+
+	got = decode(t, client.Get("http://api.example.com/v1").Do())
+	if got["host"] != "api.example.com" || got["uri"] != "/v1" {
+		t.Errorf("absolute URL not used: %v", got)
+	}
+}
+
+func TestCookiesAreKeptBetweenRequests(t *testing.T) {
+	client := expresstest.New(t, echo)
+	client.Post("/login").Expect(200)
+
+	if cookies := client.Cookies(); len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Fatalf("expected the session cookie, got %v", cookies)
+	}
+	got := decode(t, client.Get("/me").Cookie("theme", "dark").Do())
+	if fmt.Sprint(got["cookies"]) != "[theme=dark session=abc]" {
+		t.Errorf("unexpected cookies %v", got["cookies"])
+	}
+
+	client.ClearCookies()
+	got = decode(t, client.Get("/me").Do())
+	if len(got["cookies"].([]any)) != 0 {
+		t.Errorf("expected no cookies after ClearCookies, got %v", got["cookies"])
+	}
+}
+
+func TestExpectationsReportFailures(t *testing.T) {
+	rec := &recorder{}
+	client := expresstest.New(rec, echo)
+
+	req := client.Get("/")
+	req.Expect(200).ExpectHeader("Content-Type", "application/json").ExpectBodyContains(`"method":"GET"`)
+	if len(rec.errors) != 0 {
+		t.Fatalf("unexpected failures %v", rec.errors)
+	}
+
+	req.Expect(404).
+		ExpectHeader("Content-Type", "text/plain").
+		ExpectBody("nope").
+		ExpectBodyContains("missing").
+		ExpectJSON(map[string]any{"method": "POST"})
+	if len(rec.errors) != 5 {
+		t.Errorf("expected 5 failures, got %d: %v", len(rec.errors), rec.errors)
+	}
+
+	rec.errors = nil
+	client.Post("/").JSON(func() {}).Do()
+	if len(rec.errors) != 1 {
+		t.Errorf("expected the encoding error to be reported, got %v", rec.errors)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	rec := &recorder{}
+	client := expresstest.New(rec, echo)
+	client.SnapshotDir = dir
+
+	client.Get("/snap").ExpectSnapshot("echo")
+	data, err := os.ReadFile(filepath.Join(dir, "echo.snap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "200\n\n{\n  ") {
+		t.Errorf("snapshot is not indented JSON:\n%s", data)
+	}
+
+	client.Get("/snap").ExpectSnapshot("echo")
+	if len(rec.errors) != 0 {
+		t.Fatalf("unexpected failures %v", rec.errors)
+	}
+	client.Get("/other").ExpectSnapshot("echo")
+	if len(rec.errors) != 1 {
+		t.Errorf("expected a snapshot mismatch, got %v", rec.errors)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/ramansharma100/express-go
 
-go 1.22.2
+go 1.23
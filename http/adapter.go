@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var allMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "CONNECT", "TRACE"}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.HandleRoutes(w, r)
+}
+
+// Handler returns the server as a standard http.Handler so it can be mounted
+// in an existing net/http server or wrapped by standard middlewares.
+func (s *Server) Handler() http.Handler {
+	return s
+}
+
+// Mount hands every request under prefix to a standard http.Handler, with the
+// prefix stripped from the URL like http.StripPrefix. Global middlewares run first.
+func (s *Server) Mount(prefix string, handler http.Handler) {
+	if handler == nil {
+		panic("Handler cannot be nil")
+	}
+	if prefix == "" || prefix[0] != '/' {
+		prefix = "/" + prefix
+	}
+	prefix = strings.TrimRight(prefix, "/")
+
+	s.AddRoute(prefix+"/*mount", func(ctx *Context) {
+		handler.ServeHTTP(ctx.Response.Writer, stripPrefix(ctx.Request.r, prefix))
+	}, allMethods)
+}
+
+func stripPrefix(r *http.Request, prefix string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+
+	r2.URL.Path = "/" + strings.TrimLeft(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if r.URL.RawPath != "" {
+		r2.URL.RawPath = "/" + strings.TrimLeft(strings.TrimPrefix(r.URL.RawPath, prefix), "/")
+	}
+	return r2
+}
+
+// WrapHandler turns a standard http.Handler into a route handler.
+func WrapHandler(handler http.Handler) Handler {
+	return func(ctx *Context) {
+		handler.ServeHTTP(ctx.Response.Writer, ctx.Request.r)
+	}
+}
+
+// WrapMiddleware turns a standard func(http.Handler) http.Handler middleware
+// into a Middleware. The writer and request it passes on are used by the rest
+// of the chain, so wrapped writers and request contexts are kept.
+func WrapMiddleware(middleware func(http.Handler) http.Handler) Middleware {
+	return func(ctx *Context, next func()) {
+		original := ctx.Response.Writer
+		defer func() {
+			ctx.Response.Writer = original
+		}()
+
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx.Response.Writer = w
+			ctx.Request.r = r
+			next()
+		})).ServeHTTP(ctx.Response.Writer, ctx.Request.r)
+	}
+}
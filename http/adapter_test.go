@@ -0,0 +1,85 @@
+package http_test
+
+import (
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func mounted(w nethttp.ResponseWriter, r *nethttp.Request) {
+	w.Write([]byte("mounted " + r.Method + " " + r.URL.Path))
+}
+
+func TestMountKeepsEarlierParamRoutesForEveryMethod(t *testing.T) {
+	app := http.New()
+	app.Post("/users/:id", func(ctx *http.Context) { ctx.Send("user " + ctx.GetParam("id")) })
+	app.Mount("/", nethttp.HandlerFunc(mounted))
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Post("/users/1").Expect(200).ExpectBody("user 1")
+		client.Get("/users/1").Expect(200).ExpectBody("mounted GET /users/1")
+		client.Delete("/other").Expect(200).ExpectBody("mounted DELETE /other")
+	})
+}
+
+func TestMountReportsConflictsForEveryMethod(t *testing.T) {
+	app := http.New()
+	app.Mount("/", nethttp.HandlerFunc(mounted))
+	captureStdout(t, func() {
+		app.Post("/users/:id", func(ctx *http.Context) {})
+	})
+
+	conflicts := app.RouteConflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %+v", conflicts)
+	}
+	if conflicts[0].Method != "POST" || conflicts[0].Kind != http.ConflictWildcard {
+		t.Errorf("unexpected conflict %+v", conflicts[0])
+	}
+}
+
+func TestMountStripsThePrefix(t *testing.T) {
+	app := http.New()
+	app.Mount("/legacy/", nethttp.HandlerFunc(mounted))
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Put("/legacy/items/2").Expect(200).ExpectBody("mounted PUT /items/2")
+		client.Get("/legacy").Expect(200).ExpectBody("mounted GET /")
+	})
+}
+
+func TestStaticServesHeadRequests(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	app := http.New()
+	app.Static("/assets", dir, nil)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/assets/hello.txt").Expect(200).ExpectBody("hello")
+		client.Head("/assets/hello.txt").Expect(200).ExpectHeader("Content-Length", "5").ExpectBody("")
+	})
+}
+
+func TestStaticHeadRouteIsTriedAfterEarlierParamRoutes(t *testing.T) {
+	router := http.NewRouter()
+	router.Head("/:file", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("X-Version", "2")
+	})
+	app := http.New()
+	app.UseRouter("/assets/v2", router)
+	app.Static("/assets", t.TempDir(), nil)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Head("/assets/v2/logo.png").Expect(200).ExpectHeader("X-Version", "2")
+	})
+}
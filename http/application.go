@@ -1,36 +1,77 @@
 package http
 
+import (
+	"io"
+	"net/http"
+)
+
 type Application struct {
-	Listen          func(port int, callback func(int, error))
-	Get             HTTPMethod
-	Post            HTTPMethod
-	Put             HTTPMethod
-	Patch           HTTPMethod
-	Delete          HTTPMethod
-	Options         HTTPMethod
-	Static          func(prefix string, dir string)
-	Use             func(middlewares ...Middleware)
-	UseRouter       func(prefix string, router *Router)
-	SetErrorHandler func(handler ErrorHandlerType)
+	Listen             func(port int, callback func(int, error))
+	Get                HTTPMethod
+	Post               HTTPMethod
+	Put                HTTPMethod
+	Patch              HTTPMethod
+	Delete             HTTPMethod
+	Options            HTTPMethod
+	WS                 func(path string, handler WSHandler) *RouteChain
+	Static             func(prefix string, root string, options *StaticOptions)
+	Use                func(middlewares ...Middleware)
+	UseRouter          func(prefix string, router *Router)
+	UseApp             func(prefix string, app *Application)
+	Host               func(pattern string, router *Router) *VirtualHost
+	Versioned          func(options *VersionOptions) *APIVersions
+	SetErrorHandler    func(handler ErrorHandlerType)
+	SetRecover         func(options *RecoverOptions)
+	SetNotFoundHandler func(handler Handler)
+	SetServerOptions   func(options *ServerOptions)
+	SetViews           func(options *ViewOptions)
+	Handler            func() http.Handler
+	Mount              func(prefix string, handler http.Handler)
+	Routes             func() []RouteEntry
+	PrintRoutes        func(w io.Writer)
+	RouteConflicts     func() []RouteConflict
+	Locals             map[string]any
+	server             *Server
 }
 
 func New() *Application {
 	server := CreateServer()
 	return &Application{
-		Listen:          server.Listen,
-		Get:             server.Get,
-		Post:            server.Post,
-		Put:             server.Put,
-		Patch:           server.Patch,
-		Delete:          server.Delete,
-		Options:         server.Options,
-		Static:          server.Static,
-		Use:             server.Use,
-		UseRouter:       server.UseRouter,
-		SetErrorHandler: server.SetErrorHandler,
+		Listen:             server.Listen,
+		Get:                server.Get,
+		Post:               server.Post,
+		Put:                server.Put,
+		Patch:              server.Patch,
+		Delete:             server.Delete,
+		Options:            server.Options,
+		WS:                 server.WS,
+		Static:             server.Static,
+		Use:                server.Use,
+		UseRouter:          server.UseRouter,
+		UseApp:             server.UseApp,
+		Host:               server.Host,
+		Versioned:          server.Versioned,
+		SetErrorHandler:    server.SetErrorHandler,
+		SetRecover:         server.SetRecover,
+		SetNotFoundHandler: server.SetNotFoundHandler,
+		SetServerOptions:   server.SetServerOptions,
+		SetViews:           server.SetViews,
+		Handler:            server.Handler,
+		Mount:              server.Mount,
+		Routes:             server.ListRoutes,
+		PrintRoutes:        server.PrintRoutes,
+		RouteConflicts:     server.RouteConflicts,
+		Locals:             server.Locals,
+		server:             server,
 	}
 }
 
+// ServeHTTP lets the application be used as a standard http.Handler,
+// e.g. with httptest or inside another server.
+func (app *Application) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	app.server.ServeHTTP(w, r)
+}
+
 func (app *Application) Group(prefix string, middlewares []Middleware, handler func(router *Router)) {
 	if prefix == "" || prefix[0] != '/' {
 		prefix = "/" + prefix
@@ -0,0 +1,533 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a complete response as kept by a CacheStore. It may be
+// served as is until ExpiresAt, and as stale (while a fresh copy is fetched in
+// the background) until StaleUntil; stores can drop it after that.
+type CachedResponse struct {
+	Status     int
+	Header     http.Header
+	Body       []byte
+	Tags       []string
+	StoredAt   time.Time
+	ExpiresAt  time.Time
+	StaleUntil time.Time
+}
+
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, entry *CachedResponse)
+	Delete(key string)
+	DeleteTag(tag string)
+}
+
+type CacheOptions struct {
+	Store                CacheStore
+	TTL                  time.Duration
+	StaleWhileRevalidate time.Duration
+	Vary                 []string
+	Key                  func(ctx *Context) string
+	MaxSize              int
+}
+
+type cacheRevalidateKey struct{}
+
+type cacheCall struct {
+	done  chan struct{}
+	entry *CachedResponse
+}
+
+type cacheState struct {
+	mu           sync.Mutex
+	calls        map[string]*cacheCall
+	revalidating map[string]bool
+}
+
+// Cache serves GET and HEAD requests from a CacheStore. Concurrent misses for the
+// same key wait for a single handler run, and stale entries are served while
+// one request refreshes them in the background.
+func Cache(options *CacheOptions) Middleware {
+	opts := CacheOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryCacheStore(1000)
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = time.Minute
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 1 << 20
+	}
+
+	state := &cacheState{
+		calls:        make(map[string]*cacheCall),
+		revalidating: make(map[string]bool),
+	}
+
+	return func(ctx *Context, next func()) {
+		r := ctx.Request.r
+		// handlers of any method may invalidate entries
+		ctx.Request.AdditionalFields["cacheStore"] = opts.Store
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next()
+			return
+		}
+
+		for _, name := range opts.Vary {
+			addVary(ctx.Response.Writer.Header(), name)
+		}
+
+		key := defaultCacheKey(r, opts.Vary)
+		if opts.Key != nil {
+			key = opts.Key(ctx)
+		}
+
+		requestCacheControl := strings.ToLower(r.Header.Get("Cache-Control"))
+		revalidating := r.Context().Value(cacheRevalidateKey{}) != nil
+		// responses to authenticated requests may be specific to the user (RFC 9111 3.5)
+		authorized := r.Header.Get("Authorization") != ""
+
+		if !revalidating && !authorized && !strings.Contains(requestCacheControl, "no-cache") && !strings.Contains(requestCacheControl, "no-store") {
+			if entry, ok := opts.Store.Get(key); ok {
+				now := time.Now()
+				if now.Before(entry.ExpiresAt) {
+					serveCached(ctx, entry, "HIT")
+					return
+				}
+				if now.Before(entry.StaleUntil) {
+					state.revalidate(ctx, key)
+					serveCached(ctx, entry, "STALE")
+					return
+				}
+			}
+		}
+
+		// HEAD responses have no body to store
+		if strings.Contains(requestCacheControl, "no-store") || r.Method == http.MethodHead {
+			next()
+			return
+		}
+
+		if !revalidating && !authorized {
+			call, leader := state.begin(key)
+			if !leader {
+				select {
+				case <-call.done:
+				case <-ctx.Context().Done():
+					return
+				}
+				if call.entry != nil {
+					serveCached(ctx, call.entry, "HIT")
+					return
+				}
+				next()
+				return
+			}
+			defer state.end(key, call)
+			ctx.Request.AdditionalFields["cacheCall"] = call
+		}
+
+		cw := &cacheWriter{wrappedWriter: wrappedWriter{ResponseWriter: ctx.Response.Writer}, maxSize: opts.MaxSize}
+		cw.Header().Set("X-Cache", "MISS")
+		withWriter(ctx, cw, next, nil)
+
+		entry := cw.entry(opts.Vary, authorized)
+		if entry == nil {
+			return
+		}
+
+		now := time.Now()
+		entry.StoredAt = now
+		entry.ExpiresAt = now.Add(opts.TTL)
+		entry.StaleUntil = entry.ExpiresAt.Add(opts.StaleWhileRevalidate)
+		entry.Tags = append([]string{r.URL.Path}, cacheTags(ctx)...)
+
+		opts.Store.Set(key, entry)
+		if call, ok := ctx.Request.AdditionalFields["cacheCall"].(*cacheCall); ok {
+			call.entry = entry
+		}
+	}
+}
+
+func (s *cacheState) begin(key string) (*cacheCall, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if call, ok := s.calls[key]; ok {
+		return call, false
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	s.calls[key] = call
+	return call, true
+}
+
+func (s *cacheState) end(key string, call *cacheCall) {
+	s.mu.Lock()
+	delete(s.calls, key)
+	s.mu.Unlock()
+	close(call.done)
+}
+
+// revalidate replays the request in the background from the cache middleware
+// on, so the middlewares before it (access logs included) do not run again.
+// The request is marked so the cache middleware skips the lookup and stores
+// the new response.
+func (s *cacheState) revalidate(ctx *Context, key string) {
+	if ctx.server == nil || len(ctx.chain) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	if s.revalidating[key] {
+		s.mu.Unlock()
+		return
+	}
+	s.revalidating[key] = true
+	s.mu.Unlock()
+
+	background := context.WithValue(context.WithoutCancel(ctx.Context()), cacheRevalidateKey{}, true)
+	replay := ctx.replay(ctx.Request.r.Clone(background), newResponseWriter(&discardResponseWriter{header: make(http.Header)}))
+	run := chainMiddlewares(ctx.chain, ctx.handler)
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.revalidating, key)
+			s.mu.Unlock()
+			if recovered := recover(); recovered != nil {
+				Logger().WithError(wrapPanic(recovered)).Error("Cache revalidation failed")
+			}
+		}()
+		run(replay)
+	}()
+}
+
+// replay copies the context for running the rest of its chain again on r,
+// keeping what earlier middlewares stored but writing to w.
+func (ctx *Context) replay(r *http.Request, w http.ResponseWriter) *Context {
+	request := *ctx.Request
+	request.r = r
+	request.AdditionalFields = maps.Clone(ctx.Request.AdditionalFields)
+	delete(request.AdditionalFields, "cacheCall")
+	return &Context{
+		Request:  &request,
+		Response: &Response{Writer: w, Headers: maps.Clone(ctx.Response.Headers)},
+		Locals:   maps.Clone(ctx.Locals),
+		server:   ctx.server,
+	}
+}
+
+func serveCached(ctx *Context, entry *CachedResponse, state string) {
+	w := ctx.Response.Writer
+	r := ctx.Request.r
+	header := w.Header()
+
+	// the request ID belongs to this request, not the one that filled the cache
+	requestIDHeader, _ := ctx.Request.AdditionalFields["requestIdHeader"].(string)
+	for name, values := range entry.Header {
+		if requestIDHeader != "" && strings.EqualFold(name, requestIDHeader) {
+			continue
+		}
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set("X-Cache", state)
+	header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+
+	if entry.Status == http.StatusOK && isFresh(r.Header, header) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		ctx.Response.StatusCode = http.StatusNotModified
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	ctx.Response.StatusCode = entry.Status
+	w.WriteHeader(entry.Status)
+	if r.Method != http.MethodHead {
+		w.Write(entry.Body)
+	}
+}
+
+// CacheTags tags the response so it can be dropped later with InvalidateCacheTag.
+// Every cached response is also tagged with its URL path.
+func (ctx *Context) CacheTags(tags ...string) {
+	ctx.Request.AdditionalFields["cacheTags"] = append(cacheTags(ctx), tags...)
+}
+
+// InvalidateCache removes entries by key from the store of the Cache middleware
+// this request passed through.
+func (ctx *Context) InvalidateCache(keys ...string) {
+	store, ok := ctx.Request.AdditionalFields["cacheStore"].(CacheStore)
+	if !ok {
+		return
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+func (ctx *Context) InvalidateCacheTag(tags ...string) {
+	store, ok := ctx.Request.AdditionalFields["cacheStore"].(CacheStore)
+	if !ok {
+		return
+	}
+	for _, tag := range tags {
+		store.DeleteTag(tag)
+	}
+}
+
+func cacheTags(ctx *Context) []string {
+	tags, _ := ctx.Request.AdditionalFields["cacheTags"].([]string)
+	return tags
+}
+
+// defaultCacheKey treats HEAD as GET and includes the host, the sorted query
+// and the values of the Vary headers, so host routed tenants never share entries.
+func defaultCacheKey(r *http.Request, vary []string) string {
+	key := CacheKey(r.Host, r.URL.Path)
+	if query := r.URL.Query(); len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+	for _, name := range vary {
+		key += "\n" + strings.ToLower(name) + ": " + r.Header.Get(name)
+	}
+	return key
+}
+
+// CacheKey returns the key the Cache middleware uses for a GET of path on host
+// when no Vary headers or custom Key function are configured.
+func CacheKey(host, path string) string {
+	return http.MethodGet + " " + strings.ToLower(host) + path
+}
+
+type cacheWriter struct {
+	wrappedWriter
+	maxSize int
+
+	header      http.Header
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+	skip        bool
+}
+
+func (cw *cacheWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		cw.status = code
+		cw.header = cw.ResponseWriter.Header().Clone()
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	n, err := cw.ResponseWriter.Write(b)
+	if !cw.skip {
+		cw.buf.Write(b[:n])
+		if err != nil || cw.buf.Len() > cw.maxSize {
+			cw.skip = true
+			cw.buf.Reset()
+		}
+	}
+	return n, err
+}
+
+// Flush marks the response as a stream; streams are never cached.
+func (cw *cacheWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	cw.skip = true
+	cw.buf.Reset()
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// entry returns the captured response, or nil when it must not be shared
+// between clients.
+func (cw *cacheWriter) entry(vary []string, authorized bool) *CachedResponse {
+	if cw.skip || !cw.wroteHeader || cw.status != http.StatusOK {
+		return nil
+	}
+
+	header := cw.header
+	if header.Get("Set-Cookie") != "" {
+		return nil
+	}
+	cacheControl := strings.ToLower(header.Get("Cache-Control"))
+	for _, directive := range []string{"no-store", "private", "no-cache"} {
+		if strings.Contains(cacheControl, directive) {
+			return nil
+		}
+	}
+	if authorized && !strings.Contains(cacheControl, "public") && !strings.Contains(cacheControl, "s-maxage") && !strings.Contains(cacheControl, "must-revalidate") {
+		return nil
+	}
+
+	// a response that varies on a header missing from the key would be served to the wrong clients
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" || !containsFold(vary, name) {
+				return nil
+			}
+		}
+	}
+
+	header.Del("X-Cache")
+	header.Del("Age")
+	header.Del("Date")
+
+	return &CachedResponse{
+		Status: cw.status,
+		Header: header,
+		Body:   append([]byte(nil), cw.buf.Bytes()...),
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+// MemoryCacheStore is an in-memory CacheStore that evicts the least recently
+// used entry once it holds maxEntries.
+type MemoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+	tags       map[string]map[string]struct{}
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CachedResponse
+}
+
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := element.Value.(*memoryCacheItem)
+	if time.Now().After(item.entry.StaleUntil) {
+		m.remove(element)
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return item.entry, true
+}
+
+func (m *MemoryCacheStore) Set(key string, entry *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+
+	m.items[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for _, tag := range entry.Tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = make(map[string]struct{})
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+
+	for m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+}
+
+func (m *MemoryCacheStore) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+}
+
+func (m *MemoryCacheStore) DeleteTag(tag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.tags[tag] {
+		if element, ok := m.items[key]; ok {
+			m.remove(element)
+		}
+	}
+	delete(m.tags, tag)
+}
+
+func (m *MemoryCacheStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+func (m *MemoryCacheStore) remove(element *list.Element) {
+	item := element.Value.(*memoryCacheItem)
+	m.order.Remove(element)
+	delete(m.items, item.key)
+	for _, tag := range item.entry.Tags {
+		delete(m.tags[tag], item.key)
+		if len(m.tags[tag]) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+}
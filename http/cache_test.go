@@ -0,0 +1,261 @@
+package http_test
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestCacheServesHits(t *testing.T) {
+	var calls atomic.Int32
+	app := http.New()
+	app.Use(http.Cache(nil))
+	app.Get("/count", func(ctx *http.Context) {
+		ctx.Send("call " + strconv.Itoa(int(calls.Add(1))))
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/count").Expect(200).ExpectHeader("X-Cache", "MISS").ExpectBody("call 1")
+		client.Get("/count").Expect(200).ExpectHeader("X-Cache", "HIT").ExpectBody("call 1")
+		client.Get("/count?page=2").Expect(200).ExpectHeader("X-Cache", "MISS").ExpectBody("call 2")
+		client.Get("/count").Header("Cache-Control", "no-cache").Expect(200).ExpectHeader("X-Cache", "MISS").ExpectBody("call 3")
+		client.Get("/count").Expect(200).ExpectHeader("X-Cache", "HIT").ExpectBody("call 3")
+	})
+}
+
+func TestCacheSkipsPrivateResponses(t *testing.T) {
+	var calls atomic.Int32
+	app := http.New()
+	app.Use(http.Cache(&http.CacheOptions{MaxSize: 16}))
+	app.Get("/private", func(ctx *http.Context) {
+		calls.Add(1)
+		ctx.Response.Writer.Header().Set("Cache-Control", "private")
+		ctx.Send("private")
+	})
+	app.Get("/cookie", func(ctx *http.Context) {
+		calls.Add(1)
+		ctx.Response.Writer.Header().Set("Set-Cookie", "session=1")
+		ctx.Send("cookie")
+	})
+	app.Get("/large", func(ctx *http.Context) {
+		calls.Add(1)
+		ctx.Send("more than sixteen bytes")
+	})
+	app.Get("/stream", func(ctx *http.Context) {
+		calls.Add(1)
+		ctx.Response.Writer.Write([]byte("chunk"))
+		ctx.Response.Writer.(interface{ Flush() }).Flush()
+	})
+	app.Get("/missing", func(ctx *http.Context) {
+		calls.Add(1)
+		ctx.Response.Writer.WriteHeader(404)
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		for _, path := range []string{"/private", "/cookie", "/large", "/stream", "/missing"} {
+			client.Get(path).ExpectHeader("X-Cache", "MISS")
+			client.Get(path).ExpectHeader("X-Cache", "MISS")
+		}
+	})
+	if calls.Load() != 10 {
+		t.Errorf("expected every request to reach the handler, got %d calls", calls.Load())
+	}
+}
+
+func TestCacheInvalidatesTags(t *testing.T) {
+	version := 1
+	store := http.NewMemoryCacheStore(10)
+	app := http.New()
+	app.Use(http.Cache(&http.CacheOptions{Store: store}))
+	app.Get("/products/:id", func(ctx *http.Context) {
+		ctx.CacheTags("products")
+		ctx.Send(ctx.GetParam("id") + " v" + strconv.Itoa(version))
+	})
+	app.Put("/products/:id", func(ctx *http.Context) {
+		version++
+		ctx.InvalidateCacheTag("products")
+		ctx.Send("updated")
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/products/1").ExpectBody("1 v1")
+		client.Get("/products/2").ExpectBody("2 v1")
+		if store.Len() != 2 {
+			t.Errorf("expected 2 entries, got %d", store.Len())
+		}
+		client.Put("/products/1").Expect(200)
+		client.Get("/products/2").ExpectHeader("X-Cache", "MISS").ExpectBody("2 v2")
+	})
+}
+
+func TestCacheServesStaleWhileRevalidating(t *testing.T) {
+	var calls atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	app := http.New()
+	app.Use(http.Cache(&http.CacheOptions{TTL: 10 * time.Millisecond, StaleWhileRevalidate: time.Minute}))
+	app.Get("/feed", func(ctx *http.Context) {
+		n := calls.Add(1)
+		ctx.Send("feed " + strconv.Itoa(int(n)))
+		if n == 2 {
+			refreshed <- struct{}{}
+		}
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/feed").ExpectBody("feed 1")
+		time.Sleep(20 * time.Millisecond)
+		client.Get("/feed").ExpectHeader("X-Cache", "STALE").ExpectBody("feed 1")
+		select {
+		case <-refreshed:
+		case <-time.After(time.Second):
+			t.Fatal("stale entry was not refreshed")
+		}
+		// the revalidation stores the entry after the handler returns
+		deadline := time.Now().Add(time.Second)
+		for {
+			res := client.Get("/feed").Response()
+			if res.Header.Get("X-Cache") == "HIT" && res.Text() == "feed 2" {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("got %s %q after the refresh", res.Header.Get("X-Cache"), res.Text())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+}
+
+func TestCacheRunsConcurrentMissesOnce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	app := http.New()
+	app.Use(http.Cache(nil))
+	app.Get("/slow", func(ctx *http.Context) {
+		calls.Add(1)
+		<-release
+		ctx.Send("slow")
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				client.Get("/slow").Expect(200).ExpectBody("slow")
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+	})
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 handler run, got %d", calls.Load())
+	}
+}
+
+func TestCacheKeepsAuthorizedResponsesPrivate(t *testing.T) {
+	app := http.New()
+	app.Use(http.Cache(nil))
+	app.Get("/profile", func(ctx *http.Context) {
+		ctx.Send("profile of " + ctx.Request.GetHeader("Authorization"))
+	})
+	app.Get("/catalog", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("Cache-Control", "public, max-age=60")
+		ctx.Send("catalog for " + ctx.Request.GetHeader("Authorization"))
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/profile").Header("Authorization", "alice").Expect(200).ExpectHeader("X-Cache", "MISS").ExpectBody("profile of alice")
+		client.Get("/profile").Header("Authorization", "bob").Expect(200).ExpectHeader("X-Cache", "MISS").ExpectBody("profile of bob")
+		client.Get("/profile").Expect(200).ExpectHeader("X-Cache", "MISS").ExpectBody("profile of ")
+
+		// a public response may be shared, but an authenticated request still reaches the handler
+		client.Get("/catalog").Header("Authorization", "alice").ExpectHeader("X-Cache", "MISS").ExpectBody("catalog for alice")
+		client.Get("/catalog").ExpectHeader("X-Cache", "HIT").ExpectBody("catalog for alice")
+		client.Get("/catalog").Header("Authorization", "bob").ExpectHeader("X-Cache", "MISS").ExpectBody("catalog for bob")
+	})
+}
+
+func TestCacheKeepsHostsApart(t *testing.T) {
+	store := http.NewMemoryCacheStore(10)
+	tenant := http.NewRouter()
+	tenant.Get("/users", func(ctx *http.Context) { ctx.Send(ctx.GetParam("tenant") + " users") })
+
+	app := http.New()
+	app.Use(http.Cache(&http.CacheOptions{Store: store}))
+	app.Host(":tenant.example.com", tenant)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("http://acme.example.com/users").ExpectHeader("X-Cache", "MISS").ExpectBody("acme users")
+		client.Get("http://globex.example.com/users").ExpectHeader("X-Cache", "MISS").ExpectBody("globex users")
+		client.Get("http://ACME.example.com/users").ExpectHeader("X-Cache", "HIT").ExpectBody("acme users")
+	})
+	if _, ok := store.Get(http.CacheKey("globex.example.com", "/users")); !ok {
+		t.Error("expected an entry for the globex host")
+	}
+}
+
+func TestCacheRevalidationSkipsEarlierMiddlewares(t *testing.T) {
+	var seen, calls atomic.Int32
+	requests := 0
+	refreshed := make(chan struct{}, 1)
+	app := http.New()
+	app.Use(func(ctx *http.Context, next func()) {
+		seen.Add(1)
+		ctx.Locals["user"] = "alice"
+		next()
+	})
+	app.Use(http.Cache(&http.CacheOptions{TTL: 10 * time.Millisecond, StaleWhileRevalidate: time.Minute}))
+	app.Get("/feed", func(ctx *http.Context) {
+		n := calls.Add(1)
+		ctx.Send("feed for " + ctx.Locals["user"].(string) + " " + strconv.Itoa(int(n)))
+		if n == 2 {
+			refreshed <- struct{}{}
+		}
+	})
+
+	client := expresstest.New(t, app)
+	output := captureStdout(t, func() {
+		client.Get("/feed").ExpectBody("feed for alice 1")
+		time.Sleep(20 * time.Millisecond)
+		client.Get("/feed").ExpectHeader("X-Cache", "STALE").ExpectBody("feed for alice 1")
+		select {
+		case <-refreshed:
+		case <-time.After(time.Second):
+			t.Fatal("stale entry was not refreshed")
+		}
+		requests = 2
+		deadline := time.Now().Add(time.Second)
+		for requests++; client.Get("/feed").Response().Text() != "feed for alice 2"; requests++ {
+			if time.Now().After(deadline) {
+				t.Fatal("the refreshed entry was not stored")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	// the middleware before the cache and the access log only see the client's requests
+	if int(seen.Load()) != requests {
+		t.Errorf("expected the first middleware to run %d times, got %d", requests, seen.Load())
+	}
+	if lines := strings.Count(output, "GET /feed"); lines != requests {
+		t.Errorf("expected %d access log lines, got %d:\n%s", requests, lines, output)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 handler runs, got %d", calls.Load())
+	}
+}
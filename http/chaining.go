@@ -1,39 +1,282 @@
 package http
 
 import (
-	"fmt"
-	"regexp"
-	"strings"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
 )
 
-func (rc *RouteChain) Name(name string) {
+// RouteInfo describes the route that matched the request, see ctx.Route.
+type RouteInfo struct {
+	Name       string
+	Method     []string
+	Pattern    string
+	Meta       map[string]any
+	Tags       []string
+	Summary    string
+	Deprecated bool
+	Timeout    time.Duration
+	BodyLimit  int64
+}
+
+func (rc *RouteChain) Name(name string) *RouteChain {
 	if name == "" {
 		panic("Route name cannot be empty")
 	}
+	return rc.update(func(route *Route) {
+		route.Name = name
+	})
+}
+
+// Use adds middlewares to this route only. They run after the global and group
+// middlewares, inside the route's Timeout and BodyLimit.
+func (rc *RouteChain) Use(middlewares ...Middleware) *RouteChain {
+	for _, middleware := range middlewares {
+		if middleware == nil {
+			panic("Middleware cannot be nil")
+		}
+	}
+	return rc.update(func(route *Route) {
+		route.routeMiddlewares = append(append([]Middleware{}, route.routeMiddlewares...), middlewares...)
+	})
+}
+
+// Meta attaches a value to the route, readable by middlewares through ctx.Route().Meta.
+func (rc *RouteChain) Meta(key string, value any) *RouteChain {
+	if key == "" {
+		panic("Meta key cannot be empty")
+	}
+	return rc.update(func(route *Route) {
+		meta := make(map[string]any, len(route.Meta)+1)
+		for k, v := range route.Meta {
+			meta[k] = v
+		}
+		meta[key] = value
+		route.Meta = meta
+	})
+}
+
+// Timeout runs the route under the Timeout middleware with default options.
+func (rc *RouteChain) Timeout(d time.Duration) *RouteChain {
+	return rc.update(func(route *Route) {
+		route.Timeout = d
+	})
+}
+
+// BodyLimit rejects request bodies larger than limit bytes with a 413.
+func (rc *RouteChain) BodyLimit(limit int64) *RouteChain {
+	return rc.update(func(route *Route) {
+		route.BodyLimit = limit
+	})
+}
+
+func (rc *RouteChain) Tags(tags ...string) *RouteChain {
+	return rc.update(func(route *Route) {
+		route.Tags = append(append([]string{}, route.Tags...), tags...)
+	})
+}
+
+func (rc *RouteChain) Summary(summary string) *RouteChain {
+	return rc.update(func(route *Route) {
+		route.Summary = summary
+	})
+}
+
+// DeprecationOptions dates a deprecation. Responses carry it in the
+// Deprecation header (RFC 9745), with Sunset (RFC 8594) and a Link to the
+// documentation when those are set.
+type DeprecationOptions struct {
+	Date   time.Time // when the resource was or will be deprecated, the time of the call if zero
+	Sunset time.Time // when the resource stops responding
+	Link   string    // URL of the deprecation notice
+}
+
+// Deprecated marks the route as deprecated; nil options date the deprecation now.
+func (rc *RouteChain) Deprecated(options *DeprecationOptions) *RouteChain {
+	deprecation := newDeprecation(options)
+	return rc.update(func(route *Route) {
+		route.Deprecated = true
+		route.deprecation = deprecation
+	})
+}
+
+func newDeprecation(options *DeprecationOptions) DeprecationOptions {
+	deprecation := DeprecationOptions{}
+	if options != nil {
+		deprecation = *options
+	}
+	if deprecation.Date.IsZero() {
+		deprecation.Date = time.Now()
+	}
+	return deprecation
+}
+
+func (d DeprecationOptions) setHeaders(header http.Header) {
+	header.Set("Deprecation", "@"+strconv.FormatInt(d.Date.Unix(), 10))
+	if !d.Sunset.IsZero() {
+		header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		header.Add("Link", "<"+d.Link+`>; rel="deprecation"; type="text/html"`)
+	}
+}
+
+// update applies a change to the registered route, and to its copies where the
+// router it belongs to is mounted.
+func (rc *RouteChain) update(update func(route *Route)) *RouteChain {
+	path := removeQueryParams(rc.path)
+	if isParameterizedRoute(path) {
+		path, _ = getParameterizedRoute(path)
+	}
 
 	if rc.router != nil {
 		for i, route := range rc.router.routes {
-			if route.Path == rc.path && fmt.Sprintf("%v", route.Method) == fmt.Sprintf("%v", rc.method) {
-				rc.router.routes[i].Name = name
+			if route.Path == path && sameMethods(route.Method, rc.method) {
+				update(&rc.router.routes[i])
 			}
 		}
-		return
+		rc.router.updateMounted(path, rc.method, update)
+		return rc
 	}
 
 	for _, m := range rc.method {
 		routes := rc.server.Routes[m]
 		for i := range routes {
-			routePattern := "^" + strings.ReplaceAll(routes[i].Path, "{", "(?P<") // convert {param} to regex group
-			routePattern = strings.ReplaceAll(routePattern, "}", ">[^/]+)") + "$"
-			matched, _ := regexp.MatchString(routePattern, rc.path)
-			if (routes[i].Path == rc.path || matched) && fmt.Sprintf("%v", routes[i].Method) == fmt.Sprintf("%v", rc.method) {
-				routes[i].Name = name
-				rc.server.Routes[m][i].Name = name
+			if routes[i].Path == path && sameMethods(routes[i].Method, rc.method) {
+				update(&rc.server.Routes[m][i])
 			}
 		}
 	}
+	return rc
+}
+
+// chain returns every middleware the route runs: global, group and mount
+// middlewares, then the route's own options and middlewares.
+func (route Route) chain() []Middleware {
+	middlewares := append([]Middleware{}, route.Middlewares...)
+	if route.Deprecated {
+		deprecation := route.deprecation
+		middlewares = append(middlewares, func(ctx *Context, next func()) {
+			deprecation.setHeaders(ctx.Response.Writer.Header())
+			next()
+		})
+	}
+	if route.BodyLimit > 0 {
+		middlewares = append(middlewares, BodyLimit(route.BodyLimit))
+	}
+	if route.Timeout > 0 {
+		middlewares = append(middlewares, Timeout(route.Timeout, nil))
+	}
+	return append(middlewares, route.routeMiddlewares...)
+}
+
+func (route Route) info() RouteInfo {
+	return RouteInfo{
+		Name:       route.Name,
+		Method:     route.Method,
+		Pattern:    route.Path,
+		Meta:       route.Meta,
+		Tags:       route.Tags,
+		Summary:    route.Summary,
+		Deprecated: route.Deprecated,
+		Timeout:    route.Timeout,
+		BodyLimit:  route.BodyLimit,
+	}
+}
+
+// Route returns the route that matched the request; it is empty for 404s.
+func (ctx *Context) Route() RouteInfo {
+	info, _ := ctx.Request.AdditionalFields["route"].(RouteInfo)
+	return info
+}
+
+// BodyLimit answers 413 when the request body is larger than limit bytes. A
+// body without Content-Length is cut off at the limit while it is read, and
+// the response the handler writes after that is replaced by the 413.
+func BodyLimit(limit int64) Middleware {
+	return func(ctx *Context, next func()) {
+		r := ctx.Request.r
+		if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+			next()
+			return
+		}
+		if r.ContentLength > limit {
+			ctx.Response.StatusCode = http.StatusRequestEntityTooLarge
+			http.Error(ctx.Response.Writer, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(ctx.Response.Writer, r.Body, limit)}
+		r.Body = body
+		lw := &bodyLimitWriter{wrappedWriter: wrappedWriter{ResponseWriter: ctx.Response.Writer}, body: body}
+		withWriter(ctx, lw, next, nil)
+
+		if body.exceeded && !lw.Written() {
+			lw.reject()
+		}
+		if lw.rejected {
+			ctx.Response.StatusCode = http.StatusRequestEntityTooLarge
+		}
+	}
+}
 
+// limitedBody records that the body went over the limit, as body parsing
+// helpers drop the read error.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
 }
 
-// TODO: Add More chaining options
-// TODO: Utilize Name for redirections
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+type bodyLimitWriter struct {
+	wrappedWriter
+	body     *limitedBody
+	rejected bool
+}
+
+func (w *bodyLimitWriter) WriteHeader(code int) {
+	if w.committed {
+		return
+	}
+	if w.body.exceeded {
+		w.reject()
+		return
+	}
+	w.committed = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *bodyLimitWriter) Write(b []byte) (int, error) {
+	if !w.committed {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLimitWriter) Flush() {
+	if !w.committed {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.rejected {
+		f.Flush()
+	}
+}
+
+func (w *bodyLimitWriter) reject() {
+	w.committed = true
+	w.rejected = true
+	http.Error(w.ResponseWriter, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+}
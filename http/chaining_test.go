@@ -0,0 +1,200 @@
+package http_test
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+// requireScope is the kind of middleware route metadata is meant for.
+func requireScope(ctx *http.Context, next func()) {
+	scope, _ := ctx.Route().Meta["scope"].(string)
+	if scope != "" && ctx.GetHeader("X-Scope") != scope {
+		ctx.Status(403)
+		return
+	}
+	next()
+}
+
+func TestRouteMetadataIsVisibleToMiddlewares(t *testing.T) {
+	var seen http.RouteInfo
+	app := http.New()
+	app.Use(requireScope)
+	app.Use(func(ctx *http.Context, next func()) {
+		seen = ctx.Route()
+		next()
+	})
+	app.Delete("/users/:id<int>", func(ctx *http.Context) { ctx.Send("deleted") }).
+		Name("users.delete").
+		Meta("scope", "admin").
+		Tags("users").
+		Tags("admin").
+		Summary("Delete a user")
+	app.Get("/public", func(ctx *http.Context) { ctx.Send("hello") })
+	app.SetNotFoundHandler(func(ctx *http.Context) {
+		seen = ctx.Route()
+		ctx.Status(404)
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Delete("/users/1").Expect(403)
+		client.Delete("/users/1").Header("X-Scope", "admin").Expect(200).ExpectBody("deleted")
+
+		if seen.Name != "users.delete" || seen.Pattern != "/users/{id<int>}" || seen.Summary != "Delete a user" ||
+			fmt.Sprint(seen.Tags) != "[users admin]" || fmt.Sprint(seen.Method) != "[DELETE]" {
+			t.Errorf("unexpected route info %+v", seen)
+		}
+
+		client.Get("/public").Expect(200)
+		if seen.Name != "" || seen.Meta != nil {
+			t.Errorf("metadata leaked to another route: %+v", seen)
+		}
+		client.Get("/missing").Expect(404)
+		if seen.Pattern != "" {
+			t.Errorf("a 404 should have no route, got %+v", seen)
+		}
+	})
+}
+
+func TestRouteMiddlewaresAndOptions(t *testing.T) {
+	order := []string{}
+	step := func(name string) http.Middleware {
+		return func(ctx *http.Context, next func()) {
+			order = append(order, name)
+			next()
+		}
+	}
+	app := http.New()
+	app.Use(step("global"))
+	app.Get("/report", func(ctx *http.Context) {
+		order = append(order, "handler")
+		ctx.Send("report")
+	}).Use(step("route 1"), step("route 2")).Deprecated(&http.DeprecationOptions{
+		Date:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Link:   "https://example.com/deprecations/report",
+	})
+	app.Get("/legacy", func(ctx *http.Context) { ctx.Send("legacy") }).Deprecated(nil)
+	app.Get("/plain", func(ctx *http.Context) { ctx.Send("plain") })
+	app.Get("/slow", func(ctx *http.Context) {
+		select {
+		case <-ctx.Context().Done():
+		case <-time.After(time.Second):
+			ctx.Send("too late")
+		}
+	}).Timeout(10 * time.Millisecond)
+	app.Post("/upload", func(ctx *http.Context) {
+		ctx.GetBody()
+		ctx.Send("stored")
+	}).BodyLimit(16)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/report").Expect(200).
+			ExpectHeader("Deprecation", "@1767225600").
+			ExpectHeader("Sunset", "Fri, 01 Jan 2027 00:00:00 GMT").
+			ExpectHeader("Link", `<https://example.com/deprecations/report>; rel="deprecation"; type="text/html"`)
+		if strings.Join(order, ",") != "global,route 1,route 2,handler" {
+			t.Errorf("unexpected order %v", order)
+		}
+		order = nil
+		client.Get("/plain").Expect(200).ExpectHeader("Deprecation", "")
+		if strings.Join(order, ",") != "global" {
+			t.Errorf("route middlewares ran for another route: %v", order)
+		}
+		if deprecation := client.Get("/legacy").Response().Header.Get("Deprecation"); !strings.HasPrefix(deprecation, "@") {
+			t.Errorf("expected a dated deprecation, got %q", deprecation)
+		}
+
+		client.Get("/slow").Expect(503)
+
+		client.Post("/upload").JSON(map[string]int{"a": 1}).Expect(200).ExpectBody("stored")
+		client.Post("/upload").JSON(map[string]string{"a": strings.Repeat("x", 32)}).Expect(413)
+
+		// without Content-Length the body is cut off while it is read, and the handler's response is replaced
+		req := httptest.NewRequest(nethttp.MethodPost, "/upload", strings.NewReader(`{"a":"`+strings.Repeat("x", 32)+`"}`))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Code != 413 || strings.Contains(rec.Body.String(), "stored") {
+			t.Errorf("expected 413 for an oversized chunked body, got %d %q", rec.Code, rec.Body.String())
+		}
+
+		req = httptest.NewRequest(nethttp.MethodPost, "/upload", strings.NewReader(`{"a":1}`))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", "application/json")
+		rec = httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Code != 200 || rec.Body.String() != "stored" {
+			t.Errorf("expected a small chunked body to pass, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestRouteChainUpdatesMountedRoutes(t *testing.T) {
+	var seen http.RouteInfo
+	router := http.NewRouter()
+	app := http.New()
+	app.Use(func(ctx *http.Context, next func()) {
+		seen = ctx.Route()
+		next()
+	})
+	app.UseRouter("/api", router)
+	router.Get("/items", func(ctx *http.Context) { ctx.Send("items") }).Name("items").Meta("cache", true)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/api/items").Expect(200)
+	})
+	if seen.Name != "items" || seen.Meta["cache"] != true || seen.Pattern != "/api/items" {
+		t.Errorf("chain changes did not reach the mounted route: %+v", seen)
+	}
+}
+
+func TestRouteChainUpdatesHeadRoutes(t *testing.T) {
+	marked := func(ctx *http.Context, next func()) {
+		ctx.Response.Writer.Header().Set("X-Route", ctx.Route().Name)
+		next()
+	}
+	ok := func(ctx *http.Context) { ctx.Response.Writer.WriteHeader(204) }
+
+	router := http.NewRouter()
+	router.Head("/status", ok).Name("status").Use(marked)
+
+	server := http.CreateServer()
+	server.Head("/ping", ok).Name("ping").Use(marked)
+	server.UseRouter("/api", router)
+
+	client := expresstest.New(t, server)
+	captureStdout(t, func() {
+		client.Head("/ping").Expect(204).ExpectHeader("X-Route", "ping")
+		client.Head("/api/status").Expect(204).ExpectHeader("X-Route", "status")
+	})
+}
+
+func TestRouteChainPanicsOnEmptyValues(t *testing.T) {
+	app := http.New()
+	chain := app.Get("/", func(ctx *http.Context) {})
+	for want, call := range map[string]func(){
+		"Route name cannot be empty": func() { chain.Name("") },
+		"Meta key cannot be empty":   func() { chain.Meta("", 1) },
+		"Middleware cannot be nil":   func() { chain.Use(nil) },
+	} {
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != want {
+					t.Errorf("expected panic %q, got %v", want, recovered)
+				}
+			}()
+			call()
+		}()
+	}
+}
@@ -0,0 +1,319 @@
+package http
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type CompressEncoder func(w io.Writer, level int) (io.WriteCloser, error)
+
+type CompressOptions struct {
+	Level                int
+	MinLength            int
+	Encoders             map[string]CompressEncoder
+	ExcludedContentTypes []string
+}
+
+// builtInEncodings are the encodings Compress ships; brotli and zstd have no
+// encoder in the standard library and have to be registered through Encoders.
+// On equal weight registered encodings win, then these in order.
+var builtInEncodings = []string{"gzip", "deflate"}
+
+var defaultExcludedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-brotli",
+	"application/zstd",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+}
+
+func Compress(options *CompressOptions) Middleware {
+	level := -1
+	minLength := 1024
+	excluded := defaultExcludedContentTypes
+	encoders := map[string]CompressEncoder{
+		"gzip": func(w io.Writer, level int) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+		// the deflate content coding is zlib-wrapped (RFC 9110), not raw DEFLATE
+		"deflate": func(w io.Writer, level int) (io.WriteCloser, error) {
+			return zlib.NewWriterLevel(w, level)
+		},
+	}
+
+	if options != nil {
+		if options.Level != 0 {
+			level = options.Level
+		}
+		if options.MinLength > 0 {
+			minLength = options.MinLength
+		}
+		if options.ExcludedContentTypes != nil {
+			excluded = options.ExcludedContentTypes
+		}
+		for name, encoder := range options.Encoders {
+			encoders[strings.ToLower(name)] = encoder
+		}
+	}
+
+	return func(ctx *Context, next func()) {
+		addVary(ctx.Response.Writer.Header(), "Accept-Encoding")
+
+		r := ctx.Request.r
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encoders)
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next()
+			return
+		}
+
+		cw := &compressWriter{
+			wrappedWriter: wrappedWriter{ResponseWriter: ctx.Response.Writer},
+			encoding:      encoding,
+			newEncoder:    encoders[encoding],
+			level:         level,
+			minLength:     minLength,
+			excluded:      excluded,
+		}
+
+		withWriter(ctx, cw, next, func() {
+			// end the encoded stream that was already started
+			if cw.committed {
+				cw.Close()
+			}
+		})
+		cw.Close()
+	}
+}
+
+type compressWriter struct {
+	wrappedWriter
+	encoding   string
+	newEncoder CompressEncoder
+	level      int
+	minLength  int
+	excluded   []string
+
+	buf         []byte
+	status      int
+	wroteHeader bool
+	encoder     io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.committed {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if cw.wroteHeader {
+		return
+	}
+	cw.status = code
+	cw.wroteHeader = true
+
+	// bodyless responses never need an encoder
+	if code < 200 || code == http.StatusNoContent || code == http.StatusNotModified || code == http.StatusPartialContent {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.committed {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) >= cw.minLength {
+			if err := cw.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush commits to compression so streamed responses are encoded chunk by chunk.
+func (cw *compressWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.committed {
+		cw.decide(true)
+	}
+	if f, ok := cw.encoder.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) Close() error {
+	if !cw.committed {
+		if !cw.wroteHeader && len(cw.buf) == 0 {
+			return nil
+		}
+		if err := cw.decide(len(cw.buf) >= cw.minLength); err != nil {
+			return err
+		}
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+	return nil
+}
+
+func (cw *compressWriter) decide(compress bool) error {
+	cw.committed = true
+	header := cw.ResponseWriter.Header()
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" && len(cw.buf) > 0 {
+		contentType = http.DetectContentType(cw.buf)
+		header.Set("Content-Type", contentType)
+	}
+
+	if compress && header.Get("Content-Encoding") == "" && !isExcludedContentType(contentType, cw.excluded) {
+		encoder, err := cw.newEncoder(cw.ResponseWriter, cw.level)
+		if err != nil {
+			return err
+		}
+		cw.encoder = encoder
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+	}
+
+	status := cw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(status)
+
+	if len(cw.buf) > 0 {
+		buf := cw.buf
+		cw.buf = nil
+		var err error
+		if cw.encoder != nil {
+			_, err = cw.encoder.Write(buf)
+		} else {
+			_, err = cw.ResponseWriter.Write(buf)
+		}
+		return err
+	}
+	return nil
+}
+
+func isExcludedContentType(contentType string, excluded []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	if mediaType == "image/svg+xml" {
+		return false
+	}
+	for _, prefix := range excluded {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding picks the encoding with the highest q-value among the ones
+// we can produce, falling back to encodingPreference order on ties.
+func negotiateEncoding(acceptEncoding string, encoders map[string]CompressEncoder) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	weights := parseAcceptEncoding(acceptEncoding)
+	wildcard, hasWildcard := weights["*"]
+
+	best := ""
+	bestWeight := 0.0
+	for _, name := range availableEncodings(encoders) {
+		weight, ok := weights[name]
+		if !ok && hasWildcard {
+			weight, ok = wildcard, true
+		}
+		if !ok || weight <= 0 {
+			continue
+		}
+		if weight > bestWeight {
+			best = name
+			bestWeight = weight
+		}
+	}
+	return best
+}
+
+func availableEncodings(encoders map[string]CompressEncoder) []string {
+	names := []string{}
+	for name := range encoders {
+		if !slices.Contains(builtInEncodings, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range builtInEncodings {
+		if _, ok := encoders[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func parseAcceptEncoding(header string) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name := part
+		weight := 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			name = strings.TrimSpace(part[:i])
+			param := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		weights[strings.ToLower(name)] = weight
+	}
+	return weights
+}
+
+func addVary(header http.Header, value string) {
+	for _, existing := range header.Values("Vary") {
+		for _, v := range strings.Split(existing, ",") {
+			v = strings.TrimSpace(v)
+			if v == "*" || strings.EqualFold(v, value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
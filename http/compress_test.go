@@ -0,0 +1,151 @@
+package http_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func gunzip(t *testing.T, body []byte) string {
+	t.Helper()
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("body is not gzip: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading gzip body: %v", err)
+	}
+	return string(data)
+}
+
+func TestCompressEncodesLargeBodies(t *testing.T) {
+	text := strings.Repeat("express ", 512)
+	app := http.New()
+	app.Use(http.Compress(nil))
+	app.Get("/text", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("ETag", `"v1"`)
+		ctx.Send(text)
+	})
+
+	client := expresstest.New(t, app)
+	var res *expresstest.Response
+	captureStdout(t, func() {
+		res = client.Get("/text").Header("Accept-Encoding", "deflate;q=0.5, gzip").
+			Expect(200).
+			ExpectHeader("Content-Encoding", "gzip").
+			ExpectHeader("Vary", "Accept-Encoding").
+			ExpectHeader("ETag", `W/"v1"`).
+			Response()
+	})
+	if got := gunzip(t, res.Body); got != text {
+		t.Errorf("decoded body has %d bytes, want %d", len(got), len(text))
+	}
+}
+
+// the deflate content coding is the zlib format, which strict clients insist on
+func TestCompressDeflateIsZlibWrapped(t *testing.T) {
+	text := strings.Repeat("express ", 512)
+	app := http.New()
+	app.Use(http.Compress(nil))
+	app.Get("/text", func(ctx *http.Context) { ctx.Send(text) })
+
+	client := expresstest.New(t, app)
+	var res *expresstest.Response
+	captureStdout(t, func() {
+		res = client.Get("/text").Header("Accept-Encoding", "deflate").Expect(200).ExpectHeader("Content-Encoding", "deflate").Response()
+	})
+	r, err := zlib.NewReader(bytes.NewReader(res.Body))
+	if err != nil {
+		t.Fatalf("body is not zlib: %v", err)
+	}
+	if data, err := io.ReadAll(r); err != nil || string(data) != text {
+		t.Errorf("decoded %d bytes, %v", len(data), err)
+	}
+}
+
+func TestCompressLeavesSomeResponsesAlone(t *testing.T) {
+	large := strings.Repeat("a", 2048)
+	app := http.New()
+	app.Use(http.Compress(nil))
+	app.Get("/small", func(ctx *http.Context) { ctx.Send("small") })
+	app.Get("/image", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("Content-Type", "image/png")
+		ctx.Response.Writer.Write([]byte(large))
+	})
+	app.Get("/large", func(ctx *http.Context) { ctx.Send(large) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/small").Header("Accept-Encoding", "gzip").Expect(200).ExpectHeader("Content-Encoding", "").ExpectBody("small")
+		client.Get("/image").Header("Accept-Encoding", "gzip").Expect(200).ExpectHeader("Content-Encoding", "").ExpectBody(large)
+		client.Get("/large").Expect(200).ExpectHeader("Content-Encoding", "").ExpectBody(large)
+		client.Get("/large").Header("Accept-Encoding", "gzip;q=0").Expect(200).ExpectHeader("Content-Encoding", "").ExpectBody(large)
+		client.Get("/large").Header("Accept-Encoding", "gzip").Header("Range", "bytes=0-9").ExpectHeader("Content-Encoding", "")
+	})
+}
+
+func TestCompressUsesRegisteredEncoders(t *testing.T) {
+	app := http.New()
+	app.Use(http.Compress(&http.CompressOptions{
+		MinLength: 1,
+		Encoders: map[string]http.CompressEncoder{
+			"upper": func(w io.Writer, level int) (io.WriteCloser, error) { return &upperWriter{w}, nil },
+		},
+	}))
+	app.Get("/", func(ctx *http.Context) { ctx.Send("hello") })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").Header("Accept-Encoding", "upper").Expect(200).ExpectHeader("Content-Encoding", "upper").ExpectBody("HELLO")
+		client.Get("/").Header("Accept-Encoding", "br").Expect(200).ExpectHeader("Content-Encoding", "").ExpectBody("hello")
+		client.Get("/").Header("Accept-Encoding", "gzip, deflate, upper").ExpectHeader("Content-Encoding", "upper")
+		client.Get("/").Header("Accept-Encoding", "deflate, gzip").ExpectHeader("Content-Encoding", "gzip")
+	})
+}
+
+type upperWriter struct{ w io.Writer }
+
+func (u *upperWriter) Write(b []byte) (int, error) { return u.w.Write(bytes.ToUpper(b)) }
+func (u *upperWriter) Close() error                { return nil }
+
+func TestCompressEncodesFlushedStreams(t *testing.T) {
+	app := http.New()
+	app.Use(http.Compress(nil))
+	app.Get("/stream", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("Content-Type", "text/plain")
+		ctx.Response.Writer.Write([]byte("first "))
+		ctx.Response.Writer.(interface{ Flush() }).Flush()
+		ctx.Response.Writer.Write([]byte("second"))
+	})
+
+	client := expresstest.New(t, app)
+	var res *expresstest.Response
+	captureStdout(t, func() {
+		res = client.Get("/stream").Header("Accept-Encoding", "gzip").Expect(200).ExpectHeader("Content-Encoding", "gzip").Response()
+	})
+	if got := gunzip(t, res.Body); got != "first second" {
+		t.Errorf("decoded body %q", got)
+	}
+}
+
+func TestCompressRestoresTheWriterWhenTheHandlerPanics(t *testing.T) {
+	app := http.New()
+	app.SetRecover(&http.RecoverOptions{Reporter: func(*http.Context, *http.PanicError) {}})
+	app.Use(http.Compress(nil))
+	app.Get("/boom", func(ctx *http.Context) { panic("boom") })
+
+	client := expresstest.New(t, app)
+	output := captureStdout(t, func() {
+		client.Get("/boom").Header("Accept-Encoding", "gzip").Expect(500).ExpectHeader("Content-Encoding", "").ExpectJSON(map[string]any{"error": "boom"})
+	})
+	if !strings.Contains(output, "GET /boom - 500") {
+		t.Errorf("expected access line for /boom:\n%s", output)
+	}
+}
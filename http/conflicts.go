@@ -0,0 +1,186 @@
+package http
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	ConflictDuplicate      = "duplicate"
+	ConflictParamCollision = "param collision"
+	ConflictWildcard       = "shadowed by wildcard"
+	ConflictShadowed       = "shadowed"
+)
+
+// RouteConflict is a route that can never be reached because a route registered
+// before it, for the same method, matches every request it would.
+type RouteConflict struct {
+	Kind            string
+	Method          string
+	Pattern         string
+	Source          string
+	ExistingPattern string
+	ExistingSource  string
+}
+
+func (c *RouteConflict) Error() string {
+	var reason string
+	switch c.Kind {
+	case ConflictDuplicate:
+		reason = "the same route is registered twice"
+	case ConflictParamCollision:
+		reason = "the paths only differ in param names"
+	case ConflictWildcard:
+		reason = "the wildcard of the first route matches every path of the second"
+	default:
+		reason = "the first route matches every path of the second"
+	}
+	return fmt.Sprintf("route conflict: %s %s (%s) is never reached, %s %s (%s) is matched first: %s",
+		c.Method, c.Pattern, c.Source, c.Method, c.ExistingPattern, c.ExistingSource, reason)
+}
+
+// RouteConflicts returns the conflicts found while routes were registered.
+func (s *Server) RouteConflicts() []RouteConflict {
+	conflicts := append([]RouteConflict{}, s.conflicts...)
+	for _, host := range s.hosts {
+		conflicts = append(conflicts, host.server.RouteConflicts()...)
+	}
+	for _, versions := range s.versions {
+		for _, version := range versions.versions {
+			conflicts = append(conflicts, version.server.RouteConflicts()...)
+		}
+	}
+	return conflicts
+}
+
+// insertRoute adds a route for one method after checking it against the routes
+// already registered. Conflicts are logged, or panic when StrictRoutes is set.
+func (s *Server) insertRoute(method string, route Route) {
+	if conflict := findConflict(method, s.Routes[method], route); conflict != nil {
+		s.conflicts = append(s.conflicts, *conflict)
+		if s.strictRoutes() {
+			panic(conflict.Error())
+		}
+		Logger().Warn(conflict.Error())
+	}
+
+	s.Routes[method] = append(s.Routes[method], route)
+	s.Routes[method] = sortRoutesWithParamsLast(s.Routes[method])
+}
+
+func (s *Server) strictRoutes() bool {
+	for server := s; server != nil; server = server.parent {
+		if server.StrictRoutes {
+			return true
+		}
+	}
+	return false
+}
+
+// findConflict returns the first existing route that shadows route. Static
+// routes are always tried first, so they only conflict with exact duplicates;
+// parameterized routes are tried in registration order.
+func findConflict(method string, routes []Route, route Route) *RouteConflict {
+	segments := parseRoutePath(route.Path)
+	for _, existing := range routes {
+		if (len(existing.Params) == 0) != (len(route.Params) == 0) {
+			continue
+		}
+
+		kind := ""
+		existingSegments := parseRoutePath(existing.Path)
+		switch {
+		case normalizeRoutePath(existing.Path) == normalizeRoutePath(route.Path):
+			kind = ConflictDuplicate
+		case len(route.Params) == 0:
+			continue
+		case !coversRoute(existingSegments, segments):
+			continue
+		case coversRoute(segments, existingSegments):
+			kind = ConflictParamCollision
+		case hasWildcard(existingSegments):
+			kind = ConflictWildcard
+		default:
+			kind = ConflictShadowed
+		}
+
+		return &RouteConflict{
+			Kind:            kind,
+			Method:          method,
+			Pattern:         route.Path,
+			Source:          route.source,
+			ExistingPattern: existing.Path,
+			ExistingSource:  existing.source,
+		}
+	}
+	return nil
+}
+
+// coversRoute reports whether every path matched by b is also matched by a.
+func coversRoute(a []routeSegment, b []routeSegment) bool {
+	for i, sa := range a {
+		if sa.wildcard {
+			return true
+		}
+		if i >= len(b) {
+			if !sa.optional {
+				return false
+			}
+			continue
+		}
+		sb := b[i]
+		// b also matches paths without this segment, or of any length
+		if sb.wildcard || (sb.optional && !sa.optional) {
+			return false
+		}
+		if !coversSegment(sa, sb) {
+			return false
+		}
+	}
+	return len(b) <= len(a)
+}
+
+func coversSegment(a routeSegment, b routeSegment) bool {
+	if a.param == "" {
+		return b.param == "" && a.literal == b.literal
+	}
+	if a.constraint == "" {
+		return true
+	}
+	if b.param == "" {
+		return regexp.MustCompile("^(?:" + constraintPattern(a.constraint) + ")$").MatchString(b.literal)
+	}
+	return a.constraint == b.constraint
+}
+
+func hasWildcard(segments []routeSegment) bool {
+	for _, segment := range segments {
+		if segment.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+var packagePath = reflect.TypeOf(Server{}).PkgPath()
+
+// callerSource returns the file:line of the first caller outside this package,
+// i.e. where the application registered the route.
+func callerSource() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePath+".") {
+			return filepath.Base(filepath.Dir(frame.File)) + "/" + filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
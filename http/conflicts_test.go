@@ -0,0 +1,85 @@
+package http_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestRouteConflictKinds(t *testing.T) {
+	handler := func(ctx *http.Context) {}
+	app := http.New()
+	// conflicts are logged with the builtin println, straight to stderr
+	app.Get("/users", handler)
+	app.Get("/users", handler)
+
+	app.Get("/users/:id", handler)
+	app.Get("/users/:name", handler)
+
+	app.Get("/files/*path", handler)
+	app.Get("/files/:name/raw", handler)
+
+	app.Get("/posts/:slug", handler)
+	app.Get("/posts/:id<int>", handler)
+
+	// none of these are shadowed
+	app.Post("/users/:id", handler)
+	app.Get("/users/me", handler)
+	app.Get("/items/:id<int>", handler)
+	app.Get("/items/:slug", handler)
+	app.Get("/archive/:year", handler)
+	app.Get("/archive/:year/:month?", handler)
+
+	got := []string{}
+	for _, conflict := range app.RouteConflicts() {
+		got = append(got, fmt.Sprintf("%s %s after %s: %s", conflict.Method, conflict.Pattern, conflict.ExistingPattern, conflict.Kind))
+	}
+	want := []string{
+		"GET /users after /users: duplicate",
+		"GET /users/{name} after /users/{id}: param collision",
+		"GET /files/{name}/raw after /files/{*path}: shadowed by wildcard",
+		"GET /posts/{id<int>} after /posts/{slug}: shadowed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected conflicts:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	conflict := app.RouteConflicts()[1]
+	if message := conflict.Error(); !strings.HasPrefix(message, "route conflict: GET /users/{name} (http/conflicts_test.go:19) is never reached, GET /users/{id} (http/conflicts_test.go:18) is matched first") {
+		t.Errorf("expected both registration sites in %q", message)
+	}
+}
+
+func TestStrictRoutesPanicOnConflict(t *testing.T) {
+	app := http.New()
+	app.SetServerOptions(&http.ServerOptions{StrictRoutes: true})
+	app.Get("/users/:id", func(ctx *http.Context) {})
+
+	defer func() {
+		message, _ := recover().(string)
+		if !strings.HasPrefix(message, "route conflict: GET /users/{name}") || !strings.Contains(message, "only differ in param names") {
+			t.Errorf("unexpected panic %q", message)
+		}
+	}()
+	app.Get("/users/:name", func(ctx *http.Context) {})
+	t.Error("expected a panic")
+}
+
+func TestStrictRoutesCoverMountedRouters(t *testing.T) {
+	router := http.NewRouter()
+	router.Get("/items", func(ctx *http.Context) {})
+
+	app := http.New()
+	app.SetServerOptions(&http.ServerOptions{StrictRoutes: true})
+	app.Get("/api/items", func(ctx *http.Context) {})
+
+	defer func() {
+		if message, _ := recover().(string); !strings.Contains(message, "the same route is registered twice") {
+			t.Errorf("unexpected panic %q", message)
+		}
+	}()
+	app.UseRouter("/api", router)
+	t.Error("expected a panic")
+}
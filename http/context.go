@@ -1,13 +1,10 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
-	"html/template"
 	"net/http"
-	"path"
-
-	"github.com/ramansharma100/express-go/utils"
 )
 
 func (ctx *Context) ParseBody() {
@@ -20,12 +17,24 @@ func (ctx *Context) ParseBody() {
 	}
 }
 
+func (ctx *Context) Context() context.Context {
+	return ctx.Request.r.Context()
+}
+
+func (ctx *Context) SetContext(c context.Context) {
+	ctx.Request.r = ctx.Request.r.WithContext(c)
+}
+
 func (ctx *Context) GetParams() map[string]string {
-	return ctx.Request.AdditionalFields["params"].(map[string]string)
+	params, ok := ctx.Request.AdditionalFields["params"].(map[string]string)
+	if !ok {
+		return map[string]string{}
+	}
+	return params
 }
 
 func (ctx *Context) GetParam(name string) string {
-	return ctx.Request.AdditionalFields["params"].(map[string]string)[name]
+	return ctx.GetParams()[name]
 }
 
 func (ctx *Context) GetHeader(name string) string {
@@ -98,18 +107,32 @@ func (ctx *Context) Status(code int) {
 }
 
 func (ctx *Context) Render(tmpl string, data any) {
-	rootDir := utils.GetRootDirectory()
-	fmt.Println("Root Directory:", rootDir)
-	filePath := path.Join(rootDir, "templates", tmpl)
-	t, err := template.ParseFiles(filePath)
-	if err != nil {
-		ctx.Response.Writer.WriteHeader(http.StatusInternalServerError)
-		ctx.Response.Writer.Write([]byte("Error parsing template: " + err.Error()))
-		return
+	layout := ""
+	if ctx.server != nil {
+		layout = ctx.server.viewSet().layout
+	}
+	ctx.RenderWithLayout(tmpl, layout, data)
+}
+
+// RenderWithLayout renders tmpl inside layout; an empty layout renders the template on its own.
+func (ctx *Context) RenderWithLayout(tmpl string, layout string, data any) {
+	views := newViewSet(nil)
+	dev := false
+	if ctx.server != nil {
+		views = ctx.server.viewSet()
+		dev = ctx.server.isDev()
 	}
 
-	if data == nil {
-		data = map[string]any{}
+	data = ctx.viewData(data)
+
+	// render into a buffer first so a failing template still produces a clean 500
+	var buf bytes.Buffer
+	if err := views.render(&buf, tmpl, data, layout, dev); err != nil {
+		ctx.Response.Writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		ctx.Response.StatusCode = http.StatusInternalServerError
+		ctx.Response.Writer.WriteHeader(http.StatusInternalServerError)
+		ctx.Response.Writer.Write([]byte("Error rendering template: " + err.Error()))
+		return
 	}
 
 	ctx.Response.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -117,13 +140,35 @@ func (ctx *Context) Render(tmpl string, data any) {
 	ctx.Response.Writer.Header().Set("Pragma", "no-cache")
 	ctx.Response.Writer.Header().Set("Expires", "0")
 
-	err = t.Execute(ctx.Response.Writer, data)
-	if err != nil {
-		ctx.Response.Writer.WriteHeader(http.StatusInternalServerError)
-		ctx.Response.Writer.Write([]byte("Error executing template: " + err.Error()))
-		return
+	ctx.Response.Writer.Write(buf.Bytes())
+}
+
+// viewData merges app locals, request locals and the handler's data, in that order of precedence.
+// Data that is not a map (e.g. a struct) is passed to the template untouched.
+func (ctx *Context) viewData(data any) any {
+	values, ok := data.(map[string]any)
+	if data != nil && !ok {
+		return data
 	}
 
+	merged := make(map[string]any)
+	// a mounted application sees its parents' locals, its own take precedence
+	servers := []*Server{}
+	for server := ctx.server; server != nil; server = server.parent {
+		servers = append([]*Server{server}, servers...)
+	}
+	for _, server := range servers {
+		for key, value := range server.Locals {
+			merged[key] = value
+		}
+	}
+	for key, value := range ctx.Locals {
+		merged[key] = value
+	}
+	for key, value := range values {
+		merged[key] = value
+	}
+	return merged
 }
 
 func (ctx *Context) Redirect(url string) {
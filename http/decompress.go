@@ -0,0 +1,139 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type DecompressDecoder func(r io.Reader) (io.ReadCloser, error)
+
+type DecompressOptions struct {
+	MaxSize  int64
+	Decoders map[string]DecompressDecoder
+}
+
+// Decompress inflates request bodies sent with a Content-Encoding. gzip and
+// deflate are built in; brotli, zstd and others need a decoder in Decoders, the
+// standard library has none. Unknown encodings get a 415 that lists the
+// supported ones in Accept-Encoding.
+func Decompress(options *DecompressOptions) Middleware {
+	maxSize := int64(10 << 20)
+	decoders := map[string]DecompressDecoder{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"x-gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": newDeflateReader,
+	}
+
+	if options != nil {
+		if options.MaxSize > 0 {
+			maxSize = options.MaxSize
+		}
+		for name, decoder := range options.Decoders {
+			decoders[strings.ToLower(name)] = decoder
+		}
+	}
+
+	supported := make([]string, 0, len(decoders))
+	for name := range decoders {
+		supported = append(supported, name)
+	}
+	sort.Strings(supported)
+	acceptEncoding := strings.Join(supported, ", ")
+
+	return func(ctx *Context, next func()) {
+		r := ctx.Request.r
+		encodings := parseContentEncoding(r.Header.Get("Content-Encoding"))
+		if len(encodings) == 0 || r.Body == nil || r.Body == http.NoBody {
+			next()
+			return
+		}
+
+		var body io.Reader = r.Body
+		closers := []io.Closer{}
+		defer func() {
+			for _, c := range closers {
+				c.Close()
+			}
+		}()
+
+		// encodings are listed in the order they were applied, so undo them backwards
+		for i := len(encodings) - 1; i >= 0; i-- {
+			decoder, ok := decoders[encodings[i]]
+			if !ok {
+				ctx.Response.Writer.Header().Set("Accept-Encoding", acceptEncoding)
+				ctx.Response.Writer.WriteHeader(http.StatusUnsupportedMediaType)
+				ctx.Response.Status(http.StatusUnsupportedMediaType)
+				ctx.Response.Writer.Write([]byte("Unsupported Content-Encoding: " + encodings[i]))
+				return
+			}
+			reader, err := decoder(body)
+			if err != nil {
+				ctx.Response.Writer.WriteHeader(http.StatusBadRequest)
+				ctx.Response.Status(http.StatusBadRequest)
+				ctx.Response.Writer.Write([]byte("Malformed " + encodings[i] + " request body"))
+				return
+			}
+			closers = append(closers, reader)
+			body = reader
+		}
+
+		// read one byte past the limit so we can tell a full body from an oversized one
+		decoded, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+		if err != nil {
+			ctx.Response.Writer.WriteHeader(http.StatusBadRequest)
+			ctx.Response.Status(http.StatusBadRequest)
+			ctx.Response.Writer.Write([]byte("Malformed request body: " + err.Error()))
+			return
+		}
+		if int64(len(decoded)) > maxSize {
+			ctx.Response.Writer.WriteHeader(http.StatusRequestEntityTooLarge)
+			ctx.Response.Status(http.StatusRequestEntityTooLarge)
+			ctx.Response.Writer.Write([]byte("Decompressed request body too large"))
+			return
+		}
+
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(decoded))
+		r.ContentLength = int64(len(decoded))
+		r.Header.Del("Content-Encoding")
+		r.Header.Set("Content-Length", strconv.Itoa(len(decoded)))
+		delete(ctx.Request.Headers, "Content-Encoding")
+		ctx.Request.Headers["Content-Length"] = strconv.Itoa(len(decoded))
+
+		next()
+	}
+}
+
+func parseContentEncoding(header string) []string {
+	encodings := []string{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part != "" && part != "identity" {
+			encodings = append(encodings, part)
+		}
+	}
+	return encodings
+}
+
+// newDeflateReader accepts both zlib-wrapped deflate (what RFC 9110 specifies)
+// and raw deflate streams, which many clients send instead.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 && header[0]&0x0f == 8 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func gzipString(s string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.String()
+}
+
+func echoJSON(ctx *http.Context) {
+	ctx.Json(map[string]any{"body": ctx.Request.GetJsonBody(), "encoding": ctx.Request.GetHeader("Content-Encoding")})
+}
+
+func TestDecompressInflatesRequestBodies(t *testing.T) {
+	var zlibBody, rawBody bytes.Buffer
+	zw := zlib.NewWriter(&zlibBody)
+	zw.Write([]byte(`{"n":2}`))
+	zw.Close()
+	fw, _ := flate.NewWriter(&rawBody, flate.DefaultCompression)
+	fw.Write([]byte(`{"n":3}`))
+	fw.Close()
+
+	app := http.New()
+	app.Use(http.Decompress(nil))
+	app.Post("/echo", echoJSON)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Post("/echo").Header("Content-Type", "application/json").Header("Content-Encoding", "gzip").Body(gzipString(`{"n":1}`)).
+			Expect(200).ExpectJSON(map[string]any{"body": map[string]any{"n": 1}, "encoding": ""})
+		client.Post("/echo").Header("Content-Type", "application/json").Header("Content-Encoding", "deflate").Body(zlibBody.String()).
+			Expect(200).ExpectJSON(map[string]any{"body": map[string]any{"n": 2}, "encoding": ""})
+		client.Post("/echo").Header("Content-Type", "application/json").Header("Content-Encoding", "deflate").Body(rawBody.String()).
+			Expect(200).ExpectJSON(map[string]any{"body": map[string]any{"n": 3}, "encoding": ""})
+		client.Post("/echo").Header("Content-Type", "application/json").Header("Content-Encoding", "gzip, gzip").Body(gzipString(gzipString(`{"n":4}`))).
+			Expect(200).ExpectJSON(map[string]any{"body": map[string]any{"n": 4}, "encoding": ""})
+	})
+}
+
+func TestDecompressRejectsBadBodies(t *testing.T) {
+	app := http.New()
+	app.Use(http.Decompress(&http.DecompressOptions{MaxSize: 1024}))
+	app.Post("/echo", echoJSON)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Post("/echo").Header("Content-Encoding", "br").Body("...").Expect(415).
+			ExpectHeader("Accept-Encoding", "deflate, gzip, x-gzip").
+			ExpectBody("Unsupported Content-Encoding: br")
+		client.Post("/echo").Header("Content-Encoding", "gzip").Body("not gzip").Expect(400)
+		client.Post("/echo").Header("Content-Encoding", "gzip").Body(gzipString(strings.Repeat("a", 1025))).
+			Expect(413).ExpectBody("Decompressed request body too large")
+	})
+}
+
+func TestDecompressUsesRegisteredDecoders(t *testing.T) {
+	app := http.New()
+	app.Use(http.Decompress(&http.DecompressOptions{
+		Decoders: map[string]http.DecompressDecoder{
+			"BR": func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil },
+		},
+	}))
+	app.Post("/echo", echoJSON)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Post("/echo").Header("Content-Type", "application/json").Header("Content-Encoding", "br").Body(`{"n":5}`).
+			Expect(200).ExpectJSON(map[string]any{"body": map[string]any{"n": 5}, "encoding": ""})
+	})
+}
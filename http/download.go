@@ -0,0 +1,206 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type SendFileOptions struct {
+	Root     string
+	FS       fs.FS
+	MaxAge   time.Duration
+	Dotfiles string
+	Headers  map[string]string
+}
+
+var ErrFileForbidden = errors.New("file is outside the allowed root")
+
+// SendFile serves a file with Content-Type, Last-Modified and Range support.
+// With Root or FS set the path is resolved inside it and can never escape it,
+// not even through a symlink; without either the path is taken as is.
+func (ctx *Context) SendFile(name string, options *SendFileOptions) error {
+	opts := SendFileOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Dotfiles == "" {
+		opts.Dotfiles = DotfilesIgnore
+	}
+
+	w := ctx.Response.Writer
+	fsys, name, err := resolveSendFile(name, &opts)
+	if err != nil {
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+		return err
+	}
+
+	if hasDotfile(name) {
+		switch opts.Dotfiles {
+		case DotfilesDeny:
+			http.Error(w, "403 Forbidden", http.StatusForbidden)
+			return ErrFileForbidden
+		case DotfilesIgnore:
+			http.NotFound(w, ctx.Request.r)
+			return fs.ErrNotExist
+		}
+	}
+
+	stat, err := fs.Stat(fsys, name)
+	if err != nil || stat.IsDir() {
+		http.NotFound(w, ctx.Request.r)
+		if err == nil {
+			err = fs.ErrNotExist
+		}
+		return err
+	}
+
+	content, err := openSeekable(fsys, name)
+	if err != nil {
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+		return err
+	}
+	defer content.Close()
+
+	if opts.MaxAge > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(opts.MaxAge.Seconds())))
+	}
+	for key, value := range opts.Headers {
+		w.Header().Set(key, value)
+	}
+
+	ctx.SendContent(stat.Name(), stat.ModTime(), content)
+	return nil
+}
+
+// SendContent serves any io.ReadSeeker, using name for the Content-Type and
+// modTime for conditional requests (zero to skip Last-Modified).
+func (ctx *Context) SendContent(name string, modTime time.Time, content io.ReadSeeker) {
+	http.ServeContent(ctx.Response.Writer, ctx.Request.r, name, modTime, content)
+}
+
+// Download sends the file as an attachment named filename (the file's own
+// name when empty).
+func (ctx *Context) Download(name string, filename string) error {
+	if filename == "" {
+		filename = filepath.Base(name)
+	}
+	ctx.Attachment(filename)
+	return ctx.SendFile(name, nil)
+}
+
+// Attachment marks the response as a download and sets the Content-Type from
+// the extension of filename.
+func (ctx *Context) Attachment(filename string) {
+	header := ctx.Response.Writer.Header()
+	if filename == "" {
+		header.Set("Content-Disposition", "attachment")
+		return
+	}
+	if contentType := mime.TypeByExtension(path.Ext(filename)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Content-Disposition", ContentDisposition("attachment", filename))
+}
+
+// ContentDisposition builds an RFC 6266 header value. Non-ASCII names get an
+// ASCII fallback in filename and the exact name in filename* (RFC 5987).
+func ContentDisposition(disposition string, filename string) string {
+	filename = path.Base(filepath.ToSlash(filename))
+
+	var fallback strings.Builder
+	ascii := true
+	for _, r := range filename {
+		switch {
+		case r == '"' || r == '\\':
+			fallback.WriteByte('_')
+		case r < 0x20 || r == 0x7f:
+			ascii = false
+			fallback.WriteByte('_')
+		case r >= utf8.RuneSelf:
+			ascii = false
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	value := disposition + `; filename="` + fallback.String() + `"`
+	if !ascii || fallback.String() != filename {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+func encodeRFC5987(value string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if isRFC5987AttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isRFC5987AttrChar(c byte) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// resolveSendFile turns the requested path into a file system and a name
+// that fs.ValidPath accepts, so ".." can never climb out of the root.
+func resolveSendFile(name string, opts *SendFileOptions) (fs.FS, string, error) {
+	if opts.FS == nil && opts.Root == "" {
+		absName, err := filepath.Abs(name)
+		if err != nil {
+			return nil, "", err
+		}
+		return os.DirFS(filepath.Dir(absName)), filepath.Base(absName), nil
+	}
+
+	slashed := filepath.ToSlash(name)
+	for _, segment := range strings.Split(slashed, "/") {
+		if segment == ".." {
+			return nil, "", ErrFileForbidden
+		}
+	}
+	clean := strings.TrimPrefix(path.Clean("/"+slashed), "/")
+	if clean == "" || !fs.ValidPath(clean) {
+		return nil, "", ErrFileForbidden
+	}
+
+	if opts.FS != nil {
+		fsys := opts.FS
+		if opts.Root != "" && opts.Root != "." {
+			sub, err := fs.Sub(opts.FS, strings.Trim(path.Clean(filepath.ToSlash(opts.Root)), "/"))
+			if err != nil {
+				return nil, "", err
+			}
+			fsys = sub
+		}
+		return fsys, clean, nil
+	}
+
+	absRoot, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, "", err
+	}
+	return dirFS(absRoot), clean, nil
+}
@@ -0,0 +1,134 @@
+package http_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestSendFileStaysInsideRoot(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"files/report.txt": "quarterly report",
+		"files/.secret":    "hidden",
+		"outside.txt":      "outside",
+	})
+	root := filepath.Join(dir, "files")
+	var lastErr error
+	app := http.New()
+	app.Get("/files/:name", func(ctx *http.Context) {
+		lastErr = ctx.SendFile(ctx.GetParam("name"), &http.SendFileOptions{
+			Root:    root,
+			MaxAge:  time.Minute,
+			Headers: map[string]string{"X-Served-By": "SendFile"},
+		})
+	})
+	app.Get("/raw", func(ctx *http.Context) {
+		ctx.SendFile(ctx.GetSearchParams()["path"], &http.SendFileOptions{Root: root})
+	})
+	app.Get("/deny", func(ctx *http.Context) {
+		ctx.SendFile(".secret", &http.SendFileOptions{Root: root, Dotfiles: http.DotfilesDeny})
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/files/report.txt").Expect(200).
+			ExpectHeader("Content-Type", "text/plain; charset=utf-8").
+			ExpectHeader("Cache-Control", "public, max-age=60").
+			ExpectHeader("X-Served-By", "SendFile").
+			ExpectBody("quarterly report")
+		client.Get("/files/report.txt").Header("Range", "bytes=10-").Expect(206).ExpectBody("report")
+
+		client.Get("/files/missing.txt").Expect(404)
+		if !errors.Is(lastErr, fs.ErrNotExist) {
+			t.Errorf("expected a not-exist error, got %v", lastErr)
+		}
+		client.Get("/files/.secret").Expect(404)
+		client.Get("/deny").Expect(403)
+
+		client.Get("/raw").Query("path", "../outside.txt").Expect(403)
+		client.Get("/raw").Query("path", "/etc/passwd").Expect(404)
+	})
+}
+
+func TestSendFileDoesNotFollowSymlinksOutOfRoot(t *testing.T) {
+	outside := writeFiles(t, map[string]string{"secret.txt": "secret"})
+	root := writeFiles(t, map[string]string{"public.txt": "public"})
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "secret.txt")); err != nil {
+		t.Skip("symlinks are not supported: ", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "outside")); err != nil {
+		t.Skip("symlinks are not supported: ", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "public.txt"), filepath.Join(root, "alias.txt")); err != nil {
+		t.Skip("symlinks are not supported: ", err)
+	}
+
+	app := http.New()
+	app.Get("/*name", func(ctx *http.Context) {
+		ctx.SendFile(ctx.GetParam("name"), &http.SendFileOptions{Root: root})
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/secret.txt").Expect(404)
+		client.Get("/outside/secret.txt").Expect(404)
+		client.Get("/alias.txt").Expect(200).ExpectBody("public")
+	})
+}
+
+func TestDownloadAndAttachment(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"data.csv": "a,b"})
+	app := http.New()
+	app.Get("/download", func(ctx *http.Context) {
+		ctx.Download(filepath.Join(dir, "data.csv"), ctx.GetSearchParams()["as"])
+	})
+	app.Get("/generated", func(ctx *http.Context) {
+		ctx.Attachment("report.pdf")
+		ctx.SendContent("report.pdf", time.Time{}, bytes.NewReader([]byte("%PDF")))
+	})
+	app.Get("/embedded", func(ctx *http.Context) {
+		ctx.SendFile("docs/readme.md", &http.SendFileOptions{FS: fstest.MapFS{
+			"docs/readme.md": {Data: []byte("# readme")},
+		}})
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/download").Expect(200).
+			ExpectHeader("Content-Disposition", `attachment; filename="data.csv"`).
+			ExpectBody("a,b")
+		client.Get("/download").Query("as", "résumé.csv").Expect(200).
+			ExpectHeader("Content-Disposition", `attachment; filename="r_sum_.csv"; filename*=UTF-8''r%C3%A9sum%C3%A9.csv`)
+
+		client.Get("/generated").Expect(200).
+			ExpectHeader("Content-Type", "application/pdf").
+			ExpectHeader("Last-Modified", "").
+			ExpectBody("%PDF")
+		client.Get("/generated").Header("Range", "bytes=1-2").Expect(206).ExpectBody("PD")
+
+		client.Get("/embedded").Expect(200).ExpectBody("# readme")
+	})
+}
+
+func TestContentDisposition(t *testing.T) {
+	cases := map[string]string{
+		"report.pdf":       `attachment; filename="report.pdf"`,
+		"../../etc/passwd": `attachment; filename="passwd"`,
+		`say "hi".txt`:     `attachment; filename="say _hi_.txt"; filename*=UTF-8''say%20%22hi%22.txt`,
+		"日本.txt":           `attachment; filename="__.txt"; filename*=UTF-8''%E6%97%A5%E6%9C%AC.txt`,
+		"line\nbreak.txt":  `attachment; filename="line_break.txt"; filename*=UTF-8''line%0Abreak.txt`,
+	}
+	for filename, want := range cases {
+		if got := http.ContentDisposition("attachment", filename); got != want {
+			t.Errorf("ContentDisposition(%q) = %s, want %s", filename, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import "net/http"
+
+// HTTPError is an error with the status code it should be answered with. The
+// default error handler responds with Status instead of 500.
+type HTTPError struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+func NewHTTPError(status int, message string) *HTTPError {
+	return &HTTPError{Status: status, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return http.StatusText(e.Status)
+	}
+	return e.Message
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+func badRequest(message string, err error) *HTTPError {
+	return &HTTPError{Status: http.StatusBadRequest, Message: message, Err: err}
+}
+
+// Error hands err to the error handler, like a panic caught by Recover but
+// without the stack report.
+func (ctx *Context) Error(err error) {
+	if err == nil {
+		return
+	}
+	ctx.handleError(err)
+}
@@ -0,0 +1,219 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ETagOptions struct {
+	Weak    bool
+	MaxSize int
+}
+
+// ETag buffers 200 responses to GET and HEAD requests, tags them with a hash
+// of the body and answers 304 when the client's copy is still fresh. Responses
+// that already carry an ETag keep it, and streamed or oversized bodies pass through.
+func ETag(options *ETagOptions) Middleware {
+	weak := false
+	maxSize := 1 << 20
+
+	if options != nil {
+		weak = options.Weak
+		if options.MaxSize > 0 {
+			maxSize = options.MaxSize
+		}
+	}
+
+	return func(ctx *Context, next func()) {
+		r := ctx.Request.r
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next()
+			return
+		}
+
+		original := ctx.Response.Writer
+		ew := &etagWriter{wrappedWriter: wrappedWriter{ResponseWriter: original}, maxSize: maxSize}
+		withWriter(ctx, ew, next, nil)
+
+		if ew.committed || (!ew.wroteHeader && ew.buf.Len() == 0) {
+			return
+		}
+
+		header := original.Header()
+		if header.Get("ETag") == "" && ew.buf.Len() > 0 {
+			header.Set("ETag", generateETag(ew.buf.Bytes(), weak))
+		}
+
+		if isFresh(r.Header, header) {
+			ew.notModified()
+			return
+		}
+		ew.commit()
+	}
+}
+
+type etagWriter struct {
+	wrappedWriter
+	maxSize int
+
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func (ew *etagWriter) WriteHeader(code int) {
+	if ew.committed {
+		ew.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if ew.wroteHeader {
+		return
+	}
+	ew.status = code
+	ew.wroteHeader = true
+
+	// only complete 200 responses can be validated
+	if code != http.StatusOK {
+		ew.commit()
+	}
+}
+
+func (ew *etagWriter) Write(b []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.committed {
+		return ew.ResponseWriter.Write(b)
+	}
+	ew.buf.Write(b)
+	if ew.buf.Len() > ew.maxSize {
+		if err := ew.commit(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush gives up on the ETag so streamed responses reach the client right away.
+func (ew *etagWriter) Flush() {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	ew.commit()
+	if f, ok := ew.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (ew *etagWriter) commit() error {
+	if ew.committed {
+		return nil
+	}
+	ew.committed = true
+
+	status := ew.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ew.ResponseWriter.WriteHeader(status)
+
+	if ew.buf.Len() > 0 {
+		_, err := ew.ResponseWriter.Write(ew.buf.Bytes())
+		ew.buf.Reset()
+		return err
+	}
+	return nil
+}
+
+func (ew *etagWriter) notModified() {
+	ew.committed = true
+	ew.buf.Reset()
+
+	header := ew.ResponseWriter.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	header.Del("Transfer-Encoding")
+	ew.ResponseWriter.WriteHeader(http.StatusNotModified)
+}
+
+func generateETag(body []byte, weak bool) string {
+	hash := sha1.Sum(body)
+	etag := `"` + strconv.FormatInt(int64(len(body)), 16) + "-" + base64.RawStdEncoding.EncodeToString(hash[:]) + `"`
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// Fresh reports whether the client's cached copy matches the validators set on
+// the response (ETag, Last-Modified), like Express's req.fresh. Handlers can
+// set their own validators and answer 304 when it returns true.
+func (ctx *Context) Fresh() bool {
+	r := ctx.Request.r
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	status := ctx.Response.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if (status < 200 || status >= 300) && status != http.StatusNotModified {
+		return false
+	}
+
+	return isFresh(r.Header, ctx.Response.Writer.Header())
+}
+
+func (ctx *Context) Stale() bool {
+	return !ctx.Fresh()
+}
+
+func isFresh(request http.Header, response http.Header) bool {
+	noneMatch := request.Get("If-None-Match")
+	modifiedSince := request.Get("If-Modified-Since")
+	if noneMatch == "" && modifiedSince == "" {
+		return false
+	}
+
+	// an end-to-end reload must always get a full response
+	if strings.Contains(strings.ToLower(request.Get("Cache-Control")), "no-cache") {
+		return false
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2)
+	if noneMatch != "" {
+		if strings.TrimSpace(noneMatch) == "*" {
+			return true
+		}
+		etag := response.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(noneMatch, ",") {
+			if weakETagMatch(strings.TrimSpace(candidate), etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	lastModified, err := http.ParseTime(response.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	since, err := http.ParseTime(modifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+func weakETagMatch(a string, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
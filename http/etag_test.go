@@ -0,0 +1,78 @@
+package http_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestETagAnswersNotModified(t *testing.T) {
+	app := http.New()
+	app.Use(http.ETag(nil))
+	app.Get("/doc", func(ctx *http.Context) { ctx.Send("document") })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		etag := client.Get("/doc").Expect(200).ExpectBody("document").Response().Header.Get("ETag")
+		if !strings.HasPrefix(etag, `"`) {
+			t.Fatalf("expected a strong ETag, got %q", etag)
+		}
+		client.Get("/doc").Header("If-None-Match", etag).Expect(304).ExpectBody("").ExpectHeader("ETag", etag)
+		client.Get("/doc").Header("If-None-Match", "W/"+etag).Expect(304)
+		client.Get("/doc").Header("If-None-Match", `"other"`).Expect(200).ExpectBody("document")
+		client.Get("/doc").Header("If-None-Match", etag).Header("Cache-Control", "no-cache").Expect(200)
+	})
+}
+
+func TestETagOptionsAndPassThrough(t *testing.T) {
+	app := http.New()
+	app.Use(http.ETag(&http.ETagOptions{Weak: true, MaxSize: 8}))
+	app.Get("/small", func(ctx *http.Context) { ctx.Send("small") })
+	app.Get("/large", func(ctx *http.Context) { ctx.Send("larger than eight bytes") })
+	app.Get("/own", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("ETag", `"v1"`)
+		ctx.Send("own")
+	})
+	app.Get("/missing", func(ctx *http.Context) {
+		ctx.Response.Writer.WriteHeader(404)
+		ctx.Response.Writer.Write([]byte("missing"))
+	})
+	app.Get("/stream", func(ctx *http.Context) {
+		ctx.Response.Writer.Write([]byte("chunk"))
+		ctx.Response.Writer.(interface{ Flush() }).Flush()
+	})
+	app.Post("/small", func(ctx *http.Context) { ctx.Send("posted") })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		if etag := client.Get("/small").Expect(200).Response().Header.Get("ETag"); !strings.HasPrefix(etag, `W/"`) {
+			t.Errorf("expected a weak ETag, got %q", etag)
+		}
+		client.Get("/large").Expect(200).ExpectHeader("ETag", "").ExpectBody("larger than eight bytes")
+		client.Get("/own").Header("If-None-Match", `"v1"`).Expect(304)
+		client.Get("/missing").Expect(404).ExpectHeader("ETag", "").ExpectBody("missing")
+		client.Get("/stream").Expect(200).ExpectHeader("ETag", "").ExpectBody("chunk")
+		client.Post("/small").Expect(200).ExpectHeader("ETag", "").ExpectBody("posted")
+	})
+}
+
+func TestFreshUsesLastModified(t *testing.T) {
+	app := http.New()
+	app.Get("/report", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		if ctx.Fresh() {
+			ctx.Response.Writer.WriteHeader(304)
+			return
+		}
+		ctx.Send("report")
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/report").Header("If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT").Expect(304)
+		client.Get("/report").Header("If-Modified-Since", "Sun, 01 Jan 2006 15:04:05 GMT").Expect(200).ExpectBody("report")
+		client.Get("/report").Expect(200).ExpectBody("report")
+	})
+}
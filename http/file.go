@@ -2,10 +2,14 @@ package http
 
 import (
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+const defaultUploadDir = "uploads"
+
 type FileObject struct {
 	Fieldname    string
 	Originalname string
@@ -74,6 +78,16 @@ func (ctx *Context) GetUploadedFiles() (map[string][]FileObject, error) {
 	if files, ok := ctx.Request.AdditionalFields["files"].(map[string][]FileObject); ok {
 		return files, nil
 	}
+	if isMultipartRequest(ctx.Request.r) && ctx.Request.r.MultipartForm == nil {
+		return ctx.saveUploadedFiles(defaultUploadDir)
+	}
 	return nil, os.ErrNotExist
 
 }
+
+func isMultipartRequest(r *http.Request) bool {
+	if r.Method != "POST" && r.Method != "PUT" && r.Method != "PATCH" {
+		return false
+	}
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
@@ -1,6 +1,9 @@
 package http
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 func validateRoute(path string, handler Handler) bool {
 	if path == "" {
@@ -13,14 +16,26 @@ func validateRoute(path string, handler Handler) bool {
 	return true
 }
 
+// getParameterizedRoute rewrites :name, :name<constraint>, :name? and a trailing
+// *name into the {...} form the router matches on.
 func getParameterizedRoute(path string) (string, []string) {
 	Params := []string{}
-	parts := strings.Split(path, "/")
+	parts := splitRoutePath(path)
 	for i, part := range parts {
 		if strings.HasPrefix(part, ":") {
-			paramName := strings.TrimPrefix(part, ":")
+			segment := parseRouteSegment("{" + strings.TrimPrefix(part, ":") + "}")
+			// fail at registration rather than on the first request
+			constraintPattern(segment.constraint)
+			Params = append(Params, segment.param)
+			parts[i] = "{" + strings.TrimPrefix(part, ":") + "}"
+		} else if strings.HasPrefix(part, "*") && i == len(parts)-1 {
+			// a trailing *name matches the rest of the path, slashes included
+			paramName := strings.TrimPrefix(part, "*")
+			if paramName == "" {
+				paramName = "wildcard"
+			}
 			Params = append(Params, paramName)
-			parts[i] = "{" + paramName + "}"
+			parts[i] = "{*" + paramName + "}"
 		}
 	}
 
@@ -29,16 +44,13 @@ func getParameterizedRoute(path string) (string, []string) {
 
 func getSearchParams(path string) map[string]string {
 	SearchParams := make(map[string]string)
-	if strings.Contains(path, "?") {
-		parts := strings.Split(path, "?")
-		if len(parts) > 1 {
-			queryParams := parts[1]
-			for _, param := range strings.Split(queryParams, "&") {
-				if strings.Contains(param, "=") {
-					keyValue := strings.SplitN(param, "=", 2)
-					if len(keyValue) == 2 {
-						SearchParams[keyValue[0]] = keyValue[1]
-					}
+	if index := queryIndex(path); index >= 0 {
+		queryParams := path[index+1:]
+		for _, param := range strings.Split(queryParams, "&") {
+			if strings.Contains(param, "=") {
+				keyValue := strings.SplitN(param, "=", 2)
+				if len(keyValue) == 2 {
+					SearchParams[keyValue[0]] = keyValue[1]
 				}
 			}
 		}
@@ -47,24 +59,21 @@ func getSearchParams(path string) map[string]string {
 }
 
 func isParameterizedRoute(path string) bool {
-	return strings.ContainsAny(path, ":")
+	return strings.ContainsAny(path, ":*")
 }
 
+// sortRoutesWithParamsLast keeps registration order within static and
+// parameterized routes, so a constrained route registered first is tried first.
 func sortRoutesWithParamsLast(routes []Route) []Route {
-	for i := 0; i < len(routes); i++ {
-		for j := i + 1; j < len(routes); j++ {
-			if len(routes[i].Params) > 0 && len(routes[j].Params) == 0 {
-				routes[i], routes[j] = routes[j], routes[i]
-			}
-		}
-	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].Params) == 0 && len(routes[j].Params) > 0
+	})
 	return routes
 }
 
 func removeQueryParams(path string) string {
-	if strings.Contains(path, "?") {
-		parts := strings.Split(path, "?")
-		return parts[0]
+	if index := queryIndex(path); index >= 0 {
+		return path[:index]
 	}
 	return path
 }
@@ -0,0 +1,31 @@
+package http_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+// captureStdout returns what fn printed to os.Stdout, e.g. access log lines.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		done <- buf.Bytes()
+	}()
+
+	fn()
+	w.Close()
+	return string(<-done)
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VirtualHost serves the routes registered for a host pattern. It inherits the
+// application's error and not-found handlers, views and dev mode unless set.
+type VirtualHost struct {
+	pattern     string
+	re          *regexp.Regexp
+	params      []string
+	server      *Server
+	middlewares []Middleware
+	builtIns    int
+}
+
+// Host routes requests whose Host header matches pattern to router. Labels
+// starting with ":" are params (":tenant.example.com", ":id<int>.example.com"),
+// available through GetParam. Requests for other hosts use the application's
+// own routes. Calling Host again with the same pattern adds another router.
+func (s *Server) Host(pattern string, router *Router) *VirtualHost {
+	if pattern == "" {
+		panic("Host pattern cannot be empty")
+	}
+	if router == nil {
+		panic("Router cannot be nil")
+	}
+	pattern = strings.ToLower(stripPort(pattern))
+
+	host := s.virtualHost(pattern)
+	router.attach(routerMount{
+		server: host.server,
+		prefix: "/",
+	})
+	return host
+}
+
+func (s *Server) virtualHost(pattern string) *VirtualHost {
+	for _, host := range s.hosts {
+		if host.pattern == pattern {
+			return host
+		}
+	}
+
+	re, params := compileHost(pattern)
+	host := &VirtualHost{
+		pattern: pattern,
+		re:      re,
+		params:  params,
+		server: &Server{
+			Routes: make(map[string][]Route),
+			Locals: make(map[string]any),
+			parent: s,
+		},
+		// like UseApp, the application's middlewares registered so far run first
+		middlewares: append([]Middleware{}, s.Middlewares...),
+		builtIns:    s.builtIns,
+	}
+	s.hosts = append(s.hosts, host)
+	return host
+}
+
+// Use adds middlewares that run for every request to the host, 404s included.
+func (h *VirtualHost) Use(middlewares ...Middleware) *VirtualHost {
+	if middlewares == nil {
+		panic("Middleware cannot be nil")
+	}
+	h.middlewares = append(h.middlewares, middlewares...)
+	return h
+}
+
+// SetNotFoundHandler handles requests to the host that no route matches.
+func (h *VirtualHost) SetNotFoundHandler(handler Handler) *VirtualHost {
+	h.server.SetNotFoundHandler(handler)
+	return h
+}
+
+func (h *VirtualHost) SetErrorHandler(handler ErrorHandlerType) *VirtualHost {
+	h.server.SetErrorHandler(handler)
+	return h
+}
+
+func (h *VirtualHost) match(host string) (map[string]string, bool) {
+	matches := h.re.FindStringSubmatch(strings.ToLower(stripPort(host)))
+	if matches == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(h.params))
+	for i, name := range h.params {
+		params[name] = matches[h.re.SubexpIndex("p"+strconv.Itoa(i))]
+	}
+	return params, true
+}
+
+func (s *Server) serveHosts(w http.ResponseWriter, r *http.Request) bool {
+	for _, host := range s.hosts {
+		params, ok := host.match(r.Host)
+		if !ok {
+			continue
+		}
+
+		ctx := s.newContext(w, r, params, nil)
+		server := host.server
+		chainMiddlewares(host.middlewares, func(ctx *Context) {
+			server.serve(ctx.Response.Writer, ctx.Request.r, ctx)
+		})(ctx)
+		return true
+	}
+	return false
+}
+
+func compileHost(pattern string) (*regexp.Regexp, []string) {
+	var b strings.Builder
+	params := []string{}
+	for i, label := range strings.Split(pattern, ".") {
+		if i > 0 {
+			b.WriteString(`\.`)
+		}
+		if !strings.HasPrefix(label, ":") {
+			b.WriteString(regexp.QuoteMeta(label))
+			continue
+		}
+		segment := parseRouteSegment("{" + label[1:] + "}")
+		if segment.param == "" {
+			panic("Host param name cannot be empty")
+		}
+		constraint := `[^.]+`
+		if segment.constraint != "" {
+			constraint = constraintPattern(segment.constraint)
+		}
+		b.WriteString("(?P<p" + strconv.Itoa(len(params)) + ">" + constraint + ")")
+		params = append(params, segment.param)
+	}
+	return regexp.MustCompile("^" + b.String() + "$"), params
+}
+
+// stripPort removes a trailing :port; unlike net.SplitHostPort it leaves
+// ":tenant.example.com" patterns alone.
+func stripPort(host string) string {
+	colon := strings.LastIndexByte(host, ':')
+	if colon < 0 || colon < strings.LastIndexByte(host, ']') || colon == len(host)-1 {
+		return host
+	}
+	if _, err := strconv.Atoi(host[colon+1:]); err != nil {
+		return host
+	}
+	return host[:colon]
+}
+
+// Subdomains returns the subdomains of the request host in reverse order, like
+// Express's req.subdomains: ["ferrets", "tobi"] for tobi.ferrets.example.com.
+func (ctx *Context) Subdomains() []string {
+	host := stripPort(ctx.Request.r.Host)
+	if host == "" || net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return []string{}
+	}
+
+	labels := strings.Split(strings.ToLower(host), ".")
+	subdomains := []string{}
+	for i := len(labels) - 3; i >= 0; i-- {
+		subdomains = append(subdomains, labels[i])
+	}
+	return subdomains
+}
@@ -0,0 +1,81 @@
+package http_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestHostRouting(t *testing.T) {
+	api := http.NewRouter()
+	api.Get("/users", func(ctx *http.Context) { ctx.Send("api users") })
+
+	tenants := http.NewRouter()
+	tenants.Get("/users/:id", func(ctx *http.Context) {
+		ctx.Send(ctx.GetParam("tenant") + " user " + ctx.GetParam("id"))
+	})
+
+	app := http.New()
+	app.Get("/users", func(ctx *http.Context) { ctx.Send("default users") })
+	app.Host("api.example.com", api).
+		Use(func(ctx *http.Context, next func()) {
+			ctx.Response.Writer.Header().Set("X-Host", "api")
+			next()
+		}).
+		SetNotFoundHandler(func(ctx *http.Context) {
+			ctx.Status(404)
+			ctx.Send("no such endpoint")
+		})
+	app.Host(":tenant<alpha>.example.com", tenants)
+
+	// routes added to the router after Host are served too
+	api.Get("/health", func(ctx *http.Context) { ctx.Send("ok") })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("http://api.example.com/users").Expect(200).ExpectHeader("X-Host", "api").ExpectBody("api users")
+		client.Get("http://API.example.com:8080/health").Expect(200).ExpectBody("ok")
+		client.Get("http://api.example.com/missing").Expect(404).ExpectHeader("X-Host", "api").ExpectBody("no such endpoint")
+
+		client.Get("http://acme.example.com/users/7").Expect(200).ExpectBody("acme user 7")
+		client.Get("http://acme.example.com/users").Expect(404)
+
+		// neither host pattern matches, so the application's own routes answer
+		client.Get("http://acme1.example.com/users").Expect(200).ExpectBody("default users")
+		client.Get("http://example.com/users").Expect(200).ExpectHeader("X-Host", "").ExpectBody("default users")
+		client.Get("http://a.b.example.com/users").Expect(200).ExpectBody("default users")
+	})
+}
+
+func TestSubdomains(t *testing.T) {
+	app := http.New()
+	app.Get("/", func(ctx *http.Context) { ctx.Send(strings.Join(ctx.Subdomains(), ",")) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("http://tobi.ferrets.example.com:3000/").ExpectBody("ferrets,tobi")
+		client.Get("http://example.com/").ExpectBody("")
+		client.Get("http://127.0.0.1:8080/").ExpectBody("")
+		client.Get("http://[::1]:8080/").ExpectBody("")
+	})
+}
+
+func TestHostPanicsOnInvalidArguments(t *testing.T) {
+	app := http.New()
+	for want, call := range map[string]func(){
+		"Host pattern cannot be empty":    func() { app.Host("", http.NewRouter()) },
+		"Router cannot be nil":            func() { app.Host("api.example.com", nil) },
+		"Host param name cannot be empty": func() { app.Host(":.example.com", http.NewRouter()) },
+	} {
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != want {
+					t.Errorf("expected panic %q, got %v", want, recovered)
+				}
+			}()
+			call()
+		}()
+	}
+}
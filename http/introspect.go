@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteEntry describes a registered route, one per method. Middlewares counts
+// the middlewares the route runs, leaving out the logger, recovery and upload
+// handling every application starts with.
+type RouteEntry struct {
+	Host        string `json:"host,omitempty"`
+	Method      string `json:"method"`
+	Pattern     string `json:"pattern"`
+	Name        string `json:"name,omitempty"`
+	Handler     string `json:"handler"`
+	Middlewares int    `json:"middlewares"`
+	Version     string `json:"version,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+}
+
+// ListRoutes returns every route of the server, including the routes of mounted
+// routers, applications, hosts and API versions with their full pattern, sorted by host,
+// pattern and method.
+func (s *Server) ListRoutes() []RouteEntry {
+	entries := s.routeEntries("", 0)
+	for _, host := range s.hosts {
+		for _, entry := range host.server.routeEntries("", userMiddlewares(len(host.middlewares), host.builtIns)) {
+			entry.Host = host.pattern
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Host != entries[j].Host {
+			return entries[i].Host < entries[j].Host
+		}
+		if entries[i].Pattern != entries[j].Pattern {
+			return entries[i].Pattern < entries[j].Pattern
+		}
+		return methodOrder(entries[i].Method) < methodOrder(entries[j].Method)
+	})
+	return entries
+}
+
+func (s *Server) routeEntries(prefix string, middlewares int) []RouteEntry {
+	entries := []RouteEntry{}
+	for method, routes := range s.Routes {
+		for _, route := range routes {
+			entries = append(entries, RouteEntry{
+				Method:      method,
+				Pattern:     joinPaths(prefix, route.Path),
+				Name:        route.Name,
+				Handler:     handlerName(route.Handler),
+				Middlewares: middlewares + userMiddlewares(len(route.chain()), route.builtIns),
+				Deprecated:  route.Deprecated,
+			})
+		}
+	}
+	for _, mount := range s.apps {
+		entries = append(entries, mount.app.routeEntries(joinPaths(prefix, mount.prefix), middlewares+userMiddlewares(len(mount.middlewares), mount.builtIns))...)
+	}
+	for _, versions := range s.versions {
+		for _, version := range versions.versions {
+			versionPrefix := joinPaths(joinPaths(prefix, versions.prefix), "v"+version.name)
+			for _, entry := range version.server.routeEntries(versionPrefix, middlewares+userMiddlewares(len(versions.middlewares), versions.builtIns)) {
+				entry.Version = version.name
+				entry.Deprecated = entry.Deprecated || version.deprecated
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// userMiddlewares leaves the built-in middlewares out of a middleware count.
+func userMiddlewares(count int, builtIns int) int {
+	if builtIns > count {
+		return count
+	}
+	return count - builtIns
+}
+
+// PrintRoutes writes the route table to w.
+func (s *Server) PrintRoutes(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tNAME\tHANDLER\tMIDDLEWARES")
+	for _, entry := range s.ListRoutes() {
+		name := entry.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", entry.Method, entry.Host+entry.Pattern, name, entry.Handler, entry.Middlewares)
+	}
+	tw.Flush()
+}
+
+// serveRouteTable answers GET /_routes with the route table in dev mode, when
+// no route of the application handles that path.
+func (s *Server) serveRouteTable(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet || r.URL.Path != "/_routes" || !s.isDev() {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.ListRoutes())
+	return true
+}
+
+// handlerName returns the package-qualified function name, e.g. "main.listUsers"
+// or "main.main.func1" for closures.
+func handlerName(handler Handler) string {
+	if handler == nil {
+		return ""
+	}
+	fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer())
+	if fn == nil {
+		return "?"
+	}
+	name := fn.Name()
+	if slash := strings.LastIndexByte(name, '/'); slash >= 0 {
+		name = name[slash+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
+
+func methodOrder(method string) int {
+	for i, m := range allMethods {
+		if m == method {
+			return i
+		}
+	}
+	return len(allMethods)
+}
@@ -0,0 +1,95 @@
+package http_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func listUsers(ctx *http.Context) { ctx.Send("users") }
+
+func noop(ctx *http.Context, next func()) { next() }
+
+func routesApp() *http.Application {
+	app := http.New()
+	app.Use(noop)
+	app.Get("/users", listUsers).Name("users.list")
+	app.Post("/users", listUsers).Use(noop)
+
+	admin := http.NewRouter()
+	admin.Delete("/users/:id<int>", listUsers)
+	app.UseRouter("/admin", admin)
+
+	billing := http.New()
+	billing.Get("/invoices", listUsers)
+	app.UseApp("/billing", billing)
+	return app
+}
+
+func TestRoutesListsEveryRoute(t *testing.T) {
+	var app *http.Application
+	output := captureStdout(t, func() { app = routesApp() })
+	if output != "" {
+		t.Errorf("registering routes should be silent, got:\n%s", output)
+	}
+
+	got := []string{}
+	for _, entry := range app.Routes() {
+		got = append(got, entry.Method+" "+entry.Pattern+" "+entry.Name+" "+entry.Handler+" "+strconv.Itoa(entry.Middlewares))
+	}
+	// the built-in middlewares every application starts with are not counted
+	want := []string{
+		"DELETE /admin/users/{id<int>}  http_test.listUsers 1",
+		"GET /billing/invoices  http_test.listUsers 1",
+		"GET /users users.list http_test.listUsers 1",
+		"POST /users  http_test.listUsers 2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected routes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPrintRoutesWritesATable(t *testing.T) {
+	var app *http.Application
+	captureStdout(t, func() { app = routesApp() })
+
+	var buf bytes.Buffer
+	app.PrintRoutes(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || strings.Join(strings.Fields(lines[0]), " ") != "METHOD PATTERN NAME HANDLER MIDDLEWARES" {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+	if strings.Join(strings.Fields(lines[3]), " ") != "GET /users users.list http_test.listUsers 1" {
+		t.Errorf("unexpected row %q", lines[3])
+	}
+	if strings.Index(lines[1], "/admin") != strings.Index(lines[0], "PATTERN") {
+		t.Errorf("columns are not aligned:\n%s", buf.String())
+	}
+}
+
+func TestRouteTableEndpointIsDevOnly(t *testing.T) {
+	var app *http.Application
+	captureStdout(t, func() { app = routesApp() })
+	client := expresstest.New(t, app)
+
+	captureStdout(t, func() {
+		client.Get("/_routes").Expect(404)
+
+		app.SetServerOptions(&http.ServerOptions{Dev: true})
+		var entries []http.RouteEntry
+		if err := client.Get("/_routes").Expect(200).Response().JSON(&entries); err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 4 || entries[2].Name != "users.list" {
+			t.Errorf("unexpected route table %+v", entries)
+		}
+		client.Post("/_routes").Expect(404)
+
+		app.Get("/_routes", func(ctx *http.Context) { ctx.Send("mine") })
+		client.Get("/_routes").Expect(200).ExpectBody("mine")
+	})
+}
@@ -84,10 +84,14 @@ func CORS(options *CorsOptions) Middleware {
 	}
 }
 
+// Logs prints an access line once the rest of the chain is done, also when it
+// panics, with the status that was actually sent.
 func Logs(options *LogOptions) Middleware {
 	return func(ctx *Context, next func()) {
-		next()
-		if options != nil && options.Enable {
+		defer func() {
+			if options == nil || !options.Enable {
+				return
+			}
 			format := options.Format
 			if format == "" {
 				format = "{{.Method}} {{.Path}} - {{.StatusCode}}"
@@ -96,20 +100,44 @@ func Logs(options *LogOptions) Middleware {
 			logData := map[string]any{
 				"Method":     ctx.Request.Method,
 				"Path":       ctx.Request.r.URL.Path,
-				"StatusCode": strconv.Itoa(ctx.Response.StatusCode),
+				"StatusCode": strconv.Itoa(responseStatus(ctx)),
 			}
 
+			requestID := ctx.GetRequestID()
+			if requestID != "" && !strings.Contains(format, "{{.RequestID}}") {
+				format = "[{{.RequestID}}] " + format
+			}
+			logData["RequestID"] = requestID
+
 			logMessage := format
 			for key, value := range logData {
 				logMessage = strings.ReplaceAll(logMessage, "{{."+key+"}}", value.(string))
 			}
 
 			fmt.Println(logMessage)
-		}
+		}()
 
+		next()
 	}
 }
 
+// responseStatus returns the status written to the client, or the one set on
+// the response when nothing was written yet.
+func responseStatus(ctx *Context) int {
+	w := ctx.Response.Writer
+	for w != nil {
+		if rw, ok := w.(*responseWriter); ok && rw.Written() {
+			return rw.Status()
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = unwrapper.Unwrap()
+	}
+	return ctx.Response.StatusCode
+}
+
 func RateLimit(options *RateLimitOptions) Middleware {
 	return func(ctx *Context, next func()) {
 		if options == nil || options.Limit <= 0 || options.Window <= 0 {
@@ -144,11 +172,9 @@ func RateLimit(options *RateLimitOptions) Middleware {
 
 func uploadFiles(uploadDir string) Middleware {
 	return func(ctx *Context, next func()) {
-		if ctx.Request.r.Method == "POST" || ctx.Request.r.Method == "PUT" || ctx.Request.r.Method == "PATCH" {
-			contentType := ctx.Request.r.Header.Get("Content-Type")
-			if len(contentType) > 0 && contentType[:19] == "multipart/form-data" {
-				_, _ = ctx.saveUploadedFiles(uploadDir)
-			}
+		// compressed uploads are parsed lazily by GetUploadedFiles once Decompress has run
+		if isMultipartRequest(ctx.Request.r) && len(parseContentEncoding(ctx.Request.r.Header.Get("Content-Encoding"))) == 0 {
+			_, _ = ctx.saveUploadedFiles(uploadDir)
 		}
 		next()
 	}
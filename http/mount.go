@@ -0,0 +1,276 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// appMount is an application mounted with UseApp. It is matched on every
+// request, so routes added to the sub-application later still resolve.
+type appMount struct {
+	prefix      string
+	pattern     *routePattern
+	app         *Server
+	middlewares []Middleware
+	builtIns    int
+}
+
+// routerMount links a router to a server or router it is used in; routes added
+// to the router afterwards are registered there as well.
+type routerMount struct {
+	server      *Server
+	router      *Router
+	prefix      string
+	middlewares []Middleware
+	builtIns    int
+}
+
+// UseApp mounts a whole application at prefix. The sub-application keeps its own
+// middlewares, error and not-found handlers, views and locals, and inherits the
+// ones it does not set from the parent. Middlewares of the parent registered
+// before the mount run first; the parent's built-in Logs, Recover and upload
+// handling replace the sub-application's own, and recovered panics still reach
+// the sub-application's error handler.
+func (s *Server) UseApp(prefix string, app *Application) {
+	if app == nil {
+		panic("Application cannot be nil")
+	}
+	if app.server == s {
+		panic("Application cannot be mounted on itself")
+	}
+
+	prefix = normalizeMountPrefix(prefix)
+	app.server.parent = s
+
+	s.apps = append(s.apps, &appMount{
+		prefix:      prefix,
+		pattern:     compileRoute(prefix, true),
+		app:         app.server,
+		middlewares: append([]Middleware{}, s.Middlewares...),
+		builtIns:    s.builtIns,
+	})
+}
+
+func (s *Server) serveApps(w http.ResponseWriter, r *http.Request, parent *Context) bool {
+	for _, mount := range s.apps {
+		params, matched, ok := mount.pattern.match(r.URL.Path)
+		if !ok {
+			continue
+		}
+		matched = strings.TrimSuffix(matched, "/")
+
+		ctx := s.newContext(w, r, params, parent)
+		ctx.setMount(mount.prefix, ctx.BaseURL()+matched)
+
+		app := mount.app
+		chainMiddlewares(ownMiddlewares(mount.middlewares, mount.builtIns, parent), func(ctx *Context) {
+			// the built-in Recover runs on the request's first context; pointing it at
+			// the sub-application, and not restoring it on a panic, has recovered
+			// panics answered by the sub-application's error handler
+			recovering := ctx
+			if ctx.root != nil {
+				recovering = ctx.root
+			}
+			previous := recovering.server
+			recovering.server = app
+			app.serve(ctx.Response.Writer, stripPrefix(ctx.Request.r, matched), ctx)
+			recovering.server = previous
+		})(ctx)
+		return true
+	}
+
+	for _, versions := range s.versions {
+		if versions.serve(w, r, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownMiddlewares leaves out the built-in middlewares at the start of middlewares
+// when the server runs as a mounted sub-application, whose parent already ran its own.
+func ownMiddlewares(middlewares []Middleware, builtIns int, parent *Context) []Middleware {
+	if parent == nil || builtIns > len(middlewares) {
+		return middlewares
+	}
+	return middlewares[builtIns:]
+}
+
+func normalizeMountPrefix(prefix string) string {
+	if prefix == "" || prefix[0] != '/' {
+		prefix = "/" + prefix
+	}
+	if len(prefix) > 1 {
+		prefix = strings.TrimRight(prefix, "/")
+	}
+	if isParameterizedRoute(prefix) {
+		prefix, _ = getParameterizedRoute(prefix)
+	}
+	return prefix
+}
+
+func routeParams(path string) []string {
+	params := []string{}
+	for _, segment := range parseRoutePath(path) {
+		if segment.param != "" {
+			params = append(params, segment.param)
+		}
+	}
+	return params
+}
+
+func joinPaths(prefix string, path string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	if path == "" || path == "/" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	if path[0] != '/' {
+		path = "/" + path
+	}
+	return prefix + path
+}
+
+// mountedBase returns the part of urlPath matched by a router's mount path
+// (which may contain params), e.g. "/users/42" for "/users/{id}".
+func mountedBase(mountPath string, urlPath string) string {
+	if mountPath == "" || mountPath == "/" {
+		return ""
+	}
+	segments := len(strings.Split(strings.Trim(mountPath, "/"), "/"))
+	urlSegments := strings.Split(strings.Trim(urlPath, "/"), "/")
+	if len(urlSegments) < segments {
+		return ""
+	}
+	return "/" + strings.Join(urlSegments[:segments], "/")
+}
+
+// attach registers the router's routes on the target and keeps the link so
+// later routes follow.
+func (r *Router) attach(mount routerMount) {
+	r.mounts = append(r.mounts, mount)
+	for _, route := range r.routes {
+		mount.add(route)
+	}
+}
+
+func (r *Router) publish(route Route) {
+	for _, mount := range r.mounts {
+		mount.add(route)
+	}
+}
+
+func (m routerMount) add(route Route) {
+	mounted := route
+	mounted.Path = joinPaths(m.prefix, route.Path)
+	mounted.mountPath = joinPaths(m.prefix, route.mountPath)
+	mounted.Middlewares = append(append([]Middleware{}, m.middlewares...), route.Middlewares...)
+	mounted.builtIns = m.builtIns + route.builtIns
+	if prefixParams := routeParams(m.prefix); len(prefixParams) > 0 {
+		mounted.Params = append(prefixParams, route.Params...)
+	}
+
+	if m.server != nil {
+		m.server.addMountedRoute(mounted)
+		return
+	}
+	m.router.routes = append(m.router.routes, mounted)
+	m.router.publish(mounted)
+}
+
+// updateMounted applies a change made through a RouteChain to the copies of
+// the route registered where the router is mounted.
+func (r *Router) updateMounted(path string, method []string, update func(route *Route)) {
+	for _, mount := range r.mounts {
+		fullPath := joinPaths(mount.prefix, path)
+		if mount.server != nil {
+			for _, m := range method {
+				for i := range mount.server.Routes[m] {
+					if mount.server.Routes[m][i].Path == fullPath {
+						update(&mount.server.Routes[m][i])
+					}
+				}
+			}
+			continue
+		}
+		for i := range mount.router.routes {
+			if mount.router.routes[i].Path == fullPath && sameMethods(mount.router.routes[i].Method, method) {
+				update(&mount.router.routes[i])
+			}
+		}
+		mount.router.updateMounted(fullPath, method, update)
+	}
+}
+
+func sameMethods(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (ctx *Context) setMount(mountPath string, baseURL string) {
+	if mountPath == "" {
+		return
+	}
+	ctx.Request.AdditionalFields["mountPath"] = mountPath
+	ctx.Request.AdditionalFields["baseUrl"] = baseURL
+}
+
+// BaseURL is the part of the URL path the current router or application is
+// mounted at, like Express's req.baseUrl ("" outside a mount).
+func (ctx *Context) BaseURL() string {
+	baseURL, _ := ctx.Request.AdditionalFields["baseUrl"].(string)
+	return baseURL
+}
+
+// MountPath is the prefix pattern the current router or application was mounted with.
+func (ctx *Context) MountPath() string {
+	mountPath, _ := ctx.Request.AdditionalFields["mountPath"].(string)
+	return mountPath
+}
+
+func (s *Server) SetNotFoundHandler(handler Handler) {
+	s.NotFoundHandler = handler
+}
+
+func (s *Server) notFoundHandler() Handler {
+	for server := s; server != nil; server = server.parent {
+		if server.NotFoundHandler != nil {
+			return server.NotFoundHandler
+		}
+	}
+	return nil
+}
+
+func (s *Server) errorHandler() ErrorHandlerType {
+	server := s
+	for !server.errorHandlerSet && server.parent != nil {
+		server = server.parent
+	}
+	return server.ErrorHandler
+}
+
+func (s *Server) viewSet() *viewSet {
+	server := s
+	for !server.viewsSet && server.parent != nil {
+		server = server.parent
+	}
+	return server.views
+}
+
+func (s *Server) isDev() bool {
+	for server := s; server != nil; server = server.parent {
+		if server.Dev {
+			return true
+		}
+	}
+	return false
+}
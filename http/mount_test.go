@@ -0,0 +1,142 @@
+package http_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func describeMount(ctx *http.Context) {
+	ctx.Send(ctx.MountPath() + " " + ctx.BaseURL() + " " + ctx.GetParam("org") + " " + ctx.GetParam("id"))
+}
+
+func TestRoutersStayLinkedAfterMounting(t *testing.T) {
+	members := http.NewRouter()
+	orgs := http.NewRouter()
+	orgs.UseRouter("/orgs/:org", members)
+
+	app := http.New()
+	app.UseRouter("/api", orgs)
+
+	// registered after both mounts
+	members.Get("/members/:id", describeMount)
+	orgs.Get("/health", func(ctx *http.Context) { ctx.Send("ok") })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/api/orgs/acme/members/7").Expect(200).ExpectBody("/api/orgs/{org} /api/orgs/acme acme 7")
+		client.Get("/api/health").Expect(200).ExpectBody("ok")
+		client.Get("/orgs/acme/members/7").Expect(404)
+	})
+}
+
+func TestMountedAppsKeepTheirOwnSettings(t *testing.T) {
+	order := []string{}
+	billing := http.New()
+	billing.Use(func(ctx *http.Context, next func()) {
+		order = append(order, "billing")
+		next()
+	})
+	billing.SetErrorHandler(func(ctx *http.Context, err error) {
+		ctx.Status(402)
+		ctx.Send("billing: " + err.Error())
+	})
+	billing.SetNotFoundHandler(func(ctx *http.Context) {
+		ctx.Status(404)
+		ctx.Send("no such invoice")
+	})
+
+	reports := http.New()
+
+	app := http.New()
+	app.Use(func(ctx *http.Context, next func()) {
+		order = append(order, "before mount")
+		next()
+	})
+	app.SetErrorHandler(func(ctx *http.Context, err error) {
+		ctx.Status(500)
+		ctx.Send("app: " + err.Error())
+	})
+	app.SetNotFoundHandler(func(ctx *http.Context) {
+		ctx.Status(404)
+		ctx.Send("app not found")
+	})
+	app.UseApp("/billing", billing)
+	app.UseApp("/tenants/:org/reports", reports)
+	app.Use(func(ctx *http.Context, next func()) {
+		order = append(order, "after mount")
+		next()
+	})
+	app.Get("/", func(ctx *http.Context) { ctx.Send("home") })
+
+	// registered after the mount
+	billing.Get("/invoices/:id", describeMount)
+	billing.Get("/fail", func(ctx *http.Context) { ctx.Error(errors.New("card declined")) })
+	reports.Get("/", describeMount)
+	reports.Get("/fail", func(ctx *http.Context) { ctx.Error(errors.New("no data")) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/billing/invoices/9").Expect(200).ExpectBody("/billing /billing  9")
+		if strings.Join(order, ",") != "before mount,billing" {
+			t.Errorf("unexpected middleware order %v", order)
+		}
+
+		client.Get("/billing/fail").Expect(402).ExpectBody("billing: card declined")
+		client.Get("/billing/nope").Expect(404).ExpectBody("no such invoice")
+
+		client.Get("/tenants/acme/reports").Expect(200).ExpectBody("/tenants/{org}/reports /tenants/acme/reports acme ")
+		client.Get("/tenants/acme/reports/fail").Expect(500).ExpectBody("app: no data")
+		client.Get("/tenants/acme/reports/nope").Expect(404).ExpectBody("app not found")
+
+		order = nil
+		client.Get("/").Expect(200).ExpectBody("home")
+		if strings.Join(order, ",") != "before mount,after mount" {
+			t.Errorf("unexpected middleware order %v", order)
+		}
+	})
+}
+
+func TestUseAppPanicsOnItself(t *testing.T) {
+	app := http.New()
+	defer func() {
+		if recovered := recover(); recovered != "Application cannot be mounted on itself" {
+			t.Errorf("unexpected panic %v", recovered)
+		}
+	}()
+	app.UseApp("/self", app)
+}
+
+func TestMountedAppsLogEachRequestOnce(t *testing.T) {
+	inner := http.New()
+	inner.Get("/x", func(ctx *http.Context) { ctx.Send("x") })
+	inner.Get("/boom", func(ctx *http.Context) { panic("boom") })
+	inner.SetErrorHandler(func(ctx *http.Context, err error) {
+		ctx.Status(418)
+		ctx.Send("inner: " + err.Error())
+	})
+
+	sub := http.New()
+	sub.UseApp("/inner", inner)
+	sub.Get("/x", func(ctx *http.Context) { ctx.Send("x") })
+
+	app := http.New()
+	app.SetRecover(&http.RecoverOptions{Reporter: func(*http.Context, *http.PanicError) {}})
+	app.UseApp("/sub", sub)
+
+	client := expresstest.New(t, app)
+	output := captureStdout(t, func() {
+		client.Get("/sub/x").Expect(200)
+		client.Get("/sub/inner/x").Expect(200)
+		client.Get("/sub/missing").Expect(404)
+		client.Get("/sub/inner/boom").Expect(418).ExpectBody("inner: boom")
+	})
+
+	want := "GET /sub/x - 200\nGET /sub/inner/x - 200\nGET /sub/missing - 404\nGET /sub/inner/boom - 418\n"
+	if output != want {
+		t.Errorf("expected one access line per request, got:\n%s", output)
+	}
+}
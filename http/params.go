@@ -0,0 +1,188 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramansharma100/express-go/utils"
+)
+
+// The Param* and Query* accessors return the zero value or the given default
+// when the value is missing or malformed. The Parse* variants return a 400
+// *HTTPError instead, which can be passed on with ctx.Error.
+
+func (ctx *Context) ParamInt(name string) int {
+	value, _ := ctx.ParseParamInt(name)
+	return value
+}
+
+func (ctx *Context) ParseParamInt(name string) (int, error) {
+	raw, ok := ctx.GetParams()[name]
+	if !ok {
+		return 0, badRequest("missing path parameter "+strconv.Quote(name), nil)
+	}
+	value, err := utils.ParseToInt(raw)
+	if err != nil {
+		return 0, invalidValue("path parameter", name, raw, "an integer", err)
+	}
+	return value, nil
+}
+
+// ParamUUID returns the param in lower case, or "" if it is not a UUID.
+func (ctx *Context) ParamUUID(name string) string {
+	value, _ := ctx.ParseParamUUID(name)
+	return value
+}
+
+func (ctx *Context) ParseParamUUID(name string) (string, error) {
+	raw, ok := ctx.GetParams()[name]
+	if !ok {
+		return "", badRequest("missing path parameter "+strconv.Quote(name), nil)
+	}
+	if !isUUID(raw) {
+		return "", invalidValue("path parameter", name, raw, "a UUID", nil)
+	}
+	return strings.ToLower(raw), nil
+}
+
+func (ctx *Context) QueryInt(name string, defaultValue int) int {
+	value, err := ctx.ParseQueryInt(name)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (ctx *Context) ParseQueryInt(name string) (int, error) {
+	raw, err := ctx.queryValue(name)
+	if err != nil {
+		return 0, err
+	}
+	value, err := utils.ParseToInt(raw)
+	if err != nil {
+		return 0, invalidValue("query parameter", name, raw, "an integer", err)
+	}
+	return value, nil
+}
+
+func (ctx *Context) QueryFloat(name string, defaultValue float64) float64 {
+	value, err := ctx.ParseQueryFloat(name)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (ctx *Context) ParseQueryFloat(name string) (float64, error) {
+	raw, err := ctx.queryValue(name)
+	if err != nil {
+		return 0, err
+	}
+	value, err := utils.ParseToFloat(raw)
+	if err != nil {
+		return 0, invalidValue("query parameter", name, raw, "a number", err)
+	}
+	return value, nil
+}
+
+// QueryBool accepts 1/0, true/false, yes/no and on/off. A key without a value
+// (?verbose) counts as true.
+func (ctx *Context) QueryBool(name string, defaultValue bool) bool {
+	value, err := ctx.ParseQueryBool(name)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (ctx *Context) ParseQueryBool(name string) (bool, error) {
+	values, ok := ctx.Request.r.URL.Query()[name]
+	if !ok || len(values) == 0 {
+		return false, badRequest("missing query parameter "+strconv.Quote(name), nil)
+	}
+	switch strings.ToLower(strings.TrimSpace(values[0])) {
+	case "", "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, invalidValue("query parameter", name, values[0], "a boolean", nil)
+}
+
+// QueryTime accepts RFC 3339 timestamps, dates (2006-01-02) and unix seconds.
+func (ctx *Context) QueryTime(name string, defaultValue time.Time) time.Time {
+	value, err := ctx.ParseQueryTime(name)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (ctx *Context) ParseQueryTime(name string) (time.Time, error) {
+	raw, err := ctx.queryValue(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if value, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return value, nil
+	}
+	if value, err := time.Parse(time.DateOnly, raw); err == nil {
+		return value, nil
+	}
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Time{}, invalidValue("query parameter", name, raw, "a time", nil)
+}
+
+// QueryList returns every value of a query key, splitting comma-separated
+// values, so ?tag=a&tag=b and ?tag=a,b both give [a b]. Empty items are dropped.
+func (ctx *Context) QueryList(name string) []string {
+	list := []string{}
+	for _, value := range ctx.Request.r.URL.Query()[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+func (ctx *Context) queryValue(name string) (string, error) {
+	values, ok := ctx.Request.r.URL.Query()[name]
+	if !ok || len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+		return "", badRequest("missing query parameter "+strconv.Quote(name), nil)
+	}
+	return strings.TrimSpace(values[0]), nil
+}
+
+func invalidValue(kind string, name string, raw string, expected string, err error) *HTTPError {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+	return badRequest("invalid "+kind+" "+strconv.Quote(name)+": "+strconv.Quote(raw)+" is not "+expected, err)
+}
+
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package http_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestTypedParamAccessors(t *testing.T) {
+	app := http.New()
+	app.Get("/users/:id", func(ctx *http.Context) {
+		ctx.Send(fmt.Sprint(ctx.ParamInt("id"), " ", ctx.ParamUUID("id") == "", " ", ctx.ParamInt("missing")))
+	})
+	app.Get("/orders/:id", func(ctx *http.Context) { ctx.Send(ctx.ParamUUID("id")) })
+	app.Get("/search", func(ctx *http.Context) {
+		ctx.Send(fmt.Sprint(
+			ctx.QueryInt("page", 1), " ",
+			ctx.QueryFloat("min", 0.5), " ",
+			ctx.QueryBool("verbose", false), " ",
+			ctx.QueryTime("since", time.Time{}).UTC().Format(time.DateOnly), " ",
+			strings.Join(ctx.QueryList("tag"), "|"),
+		))
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/users/42").Expect(200).ExpectBody("42 true 0")
+		client.Get("/users/abc").Expect(200).ExpectBody("0 true 0")
+		client.Get("/orders/0190A6C4-2B3E-7C1D-9F00-1234567890AB").ExpectBody("0190a6c4-2b3e-7c1d-9f00-1234567890ab")
+
+		client.Get("/search").ExpectBody("1 0.5 false 0001-01-01 ")
+		client.Get("/search?page=3&min=2.25&verbose&since=2024-05-01&tag=a,b&tag=c&tag=").
+			ExpectBody("3 2.25 true 2024-05-01 a|b|c")
+		client.Get("/search?page=x&min=y&verbose=maybe&since=1714521600").
+			ExpectBody("1 0.5 false 2024-05-01 ")
+		client.Get("/search?verbose=off&since=2024-05-01T10:00:00Z").ExpectBody("1 0.5 false 2024-05-01 ")
+	})
+}
+
+func TestParseVariantsAnswerBadRequest(t *testing.T) {
+	var lastErr error
+	app := http.New()
+	app.Get("/users/:id", func(ctx *http.Context) {
+		id, err := ctx.ParseParamInt("id")
+		if lastErr = err; err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.Send(strconv.Itoa(id))
+	})
+	app.Get("/search", func(ctx *http.Context) {
+		if _, err := ctx.ParseQueryBool("verbose"); err != nil {
+			ctx.Error(err)
+			return
+		}
+		_, err := ctx.ParseQueryTime("since")
+		ctx.Error(err)
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/users/7").Expect(200).ExpectBody("7")
+
+		client.Get("/users/99999999999999999999").Expect(400).
+			ExpectJSON(map[string]any{"error": `invalid path parameter "id": "99999999999999999999" is not an integer`})
+		var httpErr *http.HTTPError
+		if !errors.As(lastErr, &httpErr) || httpErr.Status != 400 || !errors.Is(lastErr, strconv.ErrRange) {
+			t.Errorf("expected a 400 HTTPError wrapping the range error, got %#v", lastErr)
+		}
+
+		client.Get("/search").Expect(400).ExpectJSON(map[string]any{"error": `missing query parameter "verbose"`})
+		client.Get("/search?verbose=1&since=later").Expect(400).
+			ExpectJSON(map[string]any{"error": `invalid query parameter "since": "later" is not a time`})
+	})
+}
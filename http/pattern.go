@@ -0,0 +1,212 @@
+package http
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// routeConstraints are the named constraints usable as :name<constraint>;
+// anything else has to be written as :name<regex(...)>.
+var routeConstraints = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"float": `-?[0-9]+(?:\.[0-9]+)?`,
+	"alpha": `[a-zA-Z]+`,
+	"alnum": `[a-zA-Z0-9]+`,
+	"slug":  `[a-z0-9]+(?:-[a-z0-9]+)*`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+type routeSegment struct {
+	literal    string
+	param      string
+	constraint string
+	optional   bool
+	wildcard   bool
+}
+
+// routePattern is a compiled route path. Params are captured in groups named
+// p0, p1... so groups inside user regex constraints don't shift them.
+type routePattern struct {
+	re     *regexp.Regexp
+	params []string
+}
+
+var (
+	routePatterns   = map[string]*routePattern{}
+	routePatternsMu sync.RWMutex
+)
+
+// compileRoute compiles a stored route path ({id}, {id<int>}, {slug?}, {*path}).
+// With prefix set the pattern also matches anything below the path, for mounts.
+func compileRoute(path string, prefix bool) *routePattern {
+	key := path
+	if prefix {
+		key += "\x00prefix"
+	}
+
+	routePatternsMu.RLock()
+	pattern, ok := routePatterns[key]
+	routePatternsMu.RUnlock()
+	if ok {
+		return pattern
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	params := []string{}
+	for _, segment := range parseRoutePath(path) {
+		if segment.param == "" {
+			b.WriteString("/" + regexp.QuoteMeta(segment.literal))
+			continue
+		}
+
+		group := "(?P<p" + strconv.Itoa(len(params)) + ">"
+		params = append(params, segment.param)
+
+		switch {
+		case segment.wildcard:
+			b.WriteString("(?:/" + group + ".*))?")
+		case segment.optional:
+			b.WriteString("(?:/" + group + constraintPattern(segment.constraint) + "))?")
+		default:
+			b.WriteString("/" + group + constraintPattern(segment.constraint) + ")")
+		}
+	}
+	if prefix {
+		b.WriteString("(?:/|$)")
+	} else {
+		b.WriteString("$")
+	}
+
+	pattern = &routePattern{re: regexp.MustCompile(b.String()), params: params}
+
+	routePatternsMu.Lock()
+	routePatterns[key] = pattern
+	routePatternsMu.Unlock()
+
+	return pattern
+}
+
+// match returns the params and the matched part of path. Optional params that
+// are absent are left out of the map.
+func (p *routePattern) match(path string) (map[string]string, string, bool) {
+	indexes := p.re.FindStringSubmatchIndex(path)
+	if indexes == nil {
+		return nil, "", false
+	}
+
+	params := make(map[string]string, len(p.params))
+	for i, name := range p.params {
+		group := p.re.SubexpIndex("p" + strconv.Itoa(i))
+		if start := indexes[2*group]; start >= 0 {
+			params[name] = path[start:indexes[2*group+1]]
+		}
+	}
+	return params, path[indexes[0]:indexes[1]], true
+}
+
+// normalizeRoutePath gives route and request paths the same shape: a leading
+// slash and no trailing one, so the root is "".
+func normalizeRoutePath(path string) string {
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+func parseRoutePath(path string) []routeSegment {
+	segments := []routeSegment{}
+	for _, part := range splitRoutePath(normalizeRoutePath(path))[1:] {
+		segments = append(segments, parseRouteSegment(part))
+	}
+	return segments
+}
+
+func parseRouteSegment(part string) routeSegment {
+	if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+		return routeSegment{literal: part}
+	}
+
+	inner := part[1 : len(part)-1]
+	if strings.HasPrefix(inner, "*") {
+		return routeSegment{param: inner[1:], wildcard: true}
+	}
+
+	segment := routeSegment{}
+	if strings.HasSuffix(inner, "?") {
+		segment.optional = true
+		inner = inner[:len(inner)-1]
+	}
+	if open := strings.IndexByte(inner, '<'); open >= 0 && strings.HasSuffix(inner, ">") {
+		segment.constraint = inner[open+1 : len(inner)-1]
+		inner = inner[:open]
+	}
+	segment.param = inner
+	return segment
+}
+
+func constraintPattern(constraint string) string {
+	if constraint == "" {
+		return `[^/]+`
+	}
+	if strings.HasPrefix(constraint, "regex(") && strings.HasSuffix(constraint, ")") {
+		return "(?:" + constraint[len("regex("):len(constraint)-1] + ")"
+	}
+	if pattern, ok := routeConstraints[constraint]; ok {
+		return pattern
+	}
+	panic("Unknown route constraint: " + constraint)
+}
+
+// splitRoutePath splits on slashes outside of <...>, so regex constraints may contain them.
+func splitRoutePath(path string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '<':
+			depth++
+		case '>':
+			if depth > 0 {
+				depth--
+			}
+		case '/':
+			if depth == 0 {
+				parts = append(parts, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, path[start:])
+}
+
+// queryIndex finds the "?" that starts a query string, skipping the ones that
+// mark optional params or belong to a regex constraint.
+func queryIndex(path string) int {
+	depth := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '<', '{':
+			depth++
+		case '>', '}':
+			if depth > 0 {
+				depth--
+			}
+		case '?':
+			if depth > 0 {
+				continue
+			}
+			// ":slug?" at the end of a segment is an optional param
+			segmentStart := strings.LastIndexByte(path[:i], '/') + 1
+			if strings.HasPrefix(path[segmentStart:], ":") && (i+1 == len(path) || path[i+1] == '/') {
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
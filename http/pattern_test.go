@@ -0,0 +1,80 @@
+package http_test
+
+import (
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func echoParams(names ...string) http.Handler {
+	return func(ctx *http.Context) {
+		text := ""
+		for _, name := range names {
+			value, ok := ctx.GetParams()[name]
+			if !ok {
+				value = "-"
+			}
+			text += name + "=" + value + ";"
+		}
+		ctx.Send(text)
+	}
+}
+
+func TestRouteConstraintsFallThrough(t *testing.T) {
+	app := http.New()
+	app.Get("/users/:id<int>", echoParams("id"))
+	app.Get("/users/:name<alpha>", func(ctx *http.Context) { ctx.Send("by name " + ctx.GetParam("name")) })
+	app.Get("/users/:other", func(ctx *http.Context) { ctx.Send("fallback " + ctx.GetParam("other")) })
+	app.Get("/files/:name<regex([a-z]+\\.txt)>", echoParams("name"))
+	app.Get("/orders/:id<uuid>", echoParams("id"))
+	app.Get("/prices/:amount<float>/:currency<regex(usd|eur)>", echoParams("amount", "currency"))
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/users/-42").Expect(200).ExpectBody("id=-42;")
+		client.Get("/users/ada").Expect(200).ExpectBody("by name ada")
+		client.Get("/users/ada_1").Expect(200).ExpectBody("fallback ada_1")
+
+		client.Get("/files/notes.txt").Expect(200).ExpectBody("name=notes.txt;")
+		client.Get("/files/Notes.txt").Expect(404)
+		client.Get("/files/notes.txt.bak").Expect(404)
+
+		client.Get("/orders/0190a6c4-2b3e-7c1d-9f00-1234567890ab").Expect(200)
+		client.Get("/orders/42").Expect(404)
+
+		client.Get("/prices/9.99/eur").Expect(200).ExpectBody("amount=9.99;currency=eur;")
+		client.Get("/prices/9.99/gbp").Expect(404)
+	})
+}
+
+func TestOptionalParamsAndWildcards(t *testing.T) {
+	app := http.New()
+	app.Get("/posts/:slug?", echoParams("slug"))
+	app.Get("/archive/:year<int>/:month<int>?", echoParams("year", "month"))
+	app.Get("/static/*path", echoParams("path"))
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/posts").Expect(200).ExpectBody("slug=-;")
+		client.Get("/posts/hello").Expect(200).ExpectBody("slug=hello;")
+		client.Get("/posts/hello/world").Expect(404)
+
+		client.Get("/archive/2024").Expect(200).ExpectBody("year=2024;month=-;")
+		client.Get("/archive/2024/05").Expect(200).ExpectBody("year=2024;month=05;")
+		client.Get("/archive/2024/may").Expect(404)
+
+		client.Get("/static/css/site/main.css").Expect(200).ExpectBody("path=css/site/main.css;")
+		client.Get("/static").Expect(200).ExpectBody("path=-;")
+	})
+}
+
+func TestUnknownConstraintPanics(t *testing.T) {
+	app := http.New()
+	defer func() {
+		if recovered := recover(); recovered != "Unknown route constraint: date" {
+			t.Errorf("unexpected panic %v", recovered)
+		}
+	}()
+	app.Get("/events/:day<date>", func(ctx *http.Context) {})
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"syscall"
+)
+
+type RecoverOptions struct {
+	DisableStack bool
+	Reporter     PanicReporter
+	Disable      bool // only used by SetRecover, to turn the built-in Recover off
+}
+
+type PanicReporter func(ctx *Context, err *PanicError)
+
+type PanicError struct {
+	Value      any
+	Stack      []byte
+	BrokenPipe bool
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+func Recover(options *RecoverOptions) Middleware {
+	reporter := LogPanicReporter
+	captureStack := true
+
+	if options != nil {
+		if options.Reporter != nil {
+			reporter = options.Reporter
+		}
+		captureStack = !options.DisableStack
+	}
+
+	return func(ctx *Context, next func()) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			err := wrapPanic(recovered)
+			if !captureStack {
+				err.Stack = nil
+			}
+
+			// net/http uses this sentinel to abort a response silently
+			if err.Value == http.ErrAbortHandler {
+				panic(http.ErrAbortHandler)
+			}
+
+			err.BrokenPipe = isBrokenPipe(err)
+
+			reporter(ctx, err)
+
+			if err.BrokenPipe || ctx.Response.HeadersSent() {
+				return
+			}
+
+			ctx.handleError(err)
+		}()
+
+		next()
+	}
+}
+
+// SetRecover configures the Recover middleware every application starts with;
+// nil restores the defaults. It also applies to routes registered before the
+// call. To use a different recovery middleware, set Disable and add your own.
+func (s *Server) SetRecover(options *RecoverOptions) {
+	s.recoverOptions = options
+}
+
+func (s *Server) builtInRecover() Middleware {
+	return func(ctx *Context, next func()) {
+		options := s.recoverOptions
+		if options != nil && options.Disable {
+			next()
+			return
+		}
+		Recover(options)(ctx, next)
+	}
+}
+
+// LogPanicReporter is the default reporter; it writes the panic and its stack to the logger.
+func LogPanicReporter(ctx *Context, err *PanicError) {
+	log := ctx.Logger().WithField("path", ctx.Request.r.URL.Path)
+	if err.BrokenPipe {
+		log.Warn("connection closed by client: " + err.Error())
+		return
+	}
+	if len(err.Stack) > 0 {
+		log = log.WithField("stack", string(err.Stack))
+	}
+	log.Error("panic recovered: " + err.Error())
+}
+
+// wrapPanic captures the stack at the point of recovery. Middlewares that have to
+// recover and re-panic (e.g. Timeout, Compress) use it so the original stack is kept.
+func wrapPanic(recovered any) *PanicError {
+	if err, ok := recovered.(*PanicError); ok {
+		return err
+	}
+	return &PanicError{Value: recovered, Stack: debug.Stack()}
+}
+
+func (ctx *Context) handleError(err error) {
+	if ctx.server != nil {
+		if handler := ctx.server.errorHandler(); handler != nil {
+			handler(ctx, err)
+			return
+		}
+	}
+
+	status := http.StatusInternalServerError
+	message := fmt.Sprintf("Internal Server Error: %v", err)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Status
+		message = httpErr.Error()
+	}
+	if id := ctx.GetRequestID(); id != "" {
+		message += " (request id: " + id + ")"
+	}
+	ctx.Response.StatusCode = status
+	ctx.Response.Writer.WriteHeader(status)
+	ctx.Response.Writer.Write([]byte(message))
+}
+
+func isBrokenPipe(err error) bool {
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "broken pipe") || strings.Contains(message, "connection reset by peer")
+}
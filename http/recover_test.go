@@ -0,0 +1,73 @@
+package http_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestRecoverAnswers500AndLogsThePanic(t *testing.T) {
+	app := http.New()
+	var reported *http.PanicError
+	app.SetRecover(&http.RecoverOptions{Reporter: func(ctx *http.Context, err *http.PanicError) { reported = err }})
+	app.Get("/boom", func(ctx *http.Context) { panic("boom") })
+	app.Get("/ok", func(ctx *http.Context) { ctx.Send("ok") })
+
+	client := expresstest.New(t, app)
+	output := captureStdout(t, func() {
+		client.Get("/boom").Expect(500).ExpectJSON(map[string]any{"error": "boom"})
+		client.Get("/ok").Expect(200)
+	})
+
+	if !strings.Contains(output, "GET /boom - 500") {
+		t.Errorf("panicking request missing from the access log:\n%s", output)
+	}
+	if !strings.Contains(output, "GET /ok - 200") {
+		t.Errorf("expected access line for /ok:\n%s", output)
+	}
+	if reported == nil || reported.Value != "boom" || len(reported.Stack) == 0 {
+		t.Errorf("reporter got %+v", reported)
+	}
+}
+
+func TestRecoverKeepsHTTPErrorStatus(t *testing.T) {
+	app := http.New()
+	app.SetRecover(&http.RecoverOptions{Reporter: func(*http.Context, *http.PanicError) {}})
+	app.Get("/teapot", func(ctx *http.Context) { panic(http.NewHTTPError(418, "short and stout")) })
+
+	expresstest.New(t, app).Get("/teapot").Expect(418).ExpectJSON(map[string]any{"error": "short and stout"})
+}
+
+func TestSetRecoverAppliesToEarlierRoutes(t *testing.T) {
+	app := http.New()
+	app.Get("/boom", func(ctx *http.Context) { panic(errors.New("boom")) })
+
+	var stack []byte
+	app.SetRecover(&http.RecoverOptions{
+		DisableStack: true,
+		Reporter:     func(ctx *http.Context, err *http.PanicError) { stack = err.Stack },
+	})
+	expresstest.New(t, app).Get("/boom").Expect(500)
+	if stack != nil {
+		t.Errorf("expected no stack with DisableStack, got %d bytes", len(stack))
+	}
+}
+
+func TestSetRecoverDisableLetsACustomRecoverHandlePanics(t *testing.T) {
+	app := http.New()
+	app.SetRecover(&http.RecoverOptions{Disable: true})
+	app.Use(func(ctx *http.Context, next func()) {
+		defer func() {
+			if recover() != nil {
+				ctx.Response.Status(503).Send("custom")
+			}
+		}()
+		next()
+	})
+	app.Get("/boom", func(ctx *http.Context) { panic("boom") })
+
+	expresstest.New(t, app).Get("/boom").Expect(503).ExpectBody("custom")
+}
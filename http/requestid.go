@@ -0,0 +1,120 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type RequestIDOptions struct {
+	Header    string
+	Generator func() string
+}
+
+const (
+	requestIDField       = "requestId"
+	requestIDHeaderField = "requestIdHeader"
+	defaultRequestHeader = "X-Request-ID"
+	maxRequestIDLength   = 128
+)
+
+func RequestID(options *RequestIDOptions) Middleware {
+	header := defaultRequestHeader
+	generator := generateRequestID
+
+	if options != nil {
+		if options.Header != "" {
+			header = options.Header
+		}
+		if options.Generator != nil {
+			generator = options.Generator
+		}
+	}
+
+	return func(ctx *Context, next func()) {
+		id := ctx.Request.r.Header.Get(header)
+		if !isValidRequestID(id) {
+			id = generator()
+		}
+
+		ctx.Request.AddField(requestIDField, id)
+		ctx.Request.AddField(requestIDHeaderField, header)
+		ctx.SetHeader(header, id)
+
+		next()
+	}
+}
+
+func (ctx *Context) GetRequestID() string {
+	id, _ := ctx.Request.AdditionalFields[requestIDField].(string)
+	return id
+}
+
+func (ctx *Context) Logger() LoggerType {
+	if id := ctx.GetRequestID(); id != "" {
+		return Logger().WithField("request_id", id)
+	}
+	return Logger()
+}
+
+// HTTPClient returns a client that forwards the current request ID to downstream services.
+func (ctx *Context) HTTPClient() *http.Client {
+	id := ctx.GetRequestID()
+	if id == "" {
+		return &http.Client{}
+	}
+
+	header, _ := ctx.Request.AdditionalFields[requestIDHeaderField].(string)
+	if header == "" {
+		header = defaultRequestHeader
+	}
+
+	return &http.Client{
+		Transport: &requestIDTransport{
+			base:   http.DefaultTransport,
+			header: header,
+			id:     id,
+		},
+	}
+}
+
+type requestIDTransport struct {
+	base   http.RoundTripper
+	header string
+	id     string
+}
+
+func (t *requestIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Header.Get(t.header) == "" {
+		r = r.Clone(r.Context())
+		r.Header.Set(t.header, t.id)
+	}
+	return t.base.RoundTrip(r)
+}
+
+// generateRequestID returns a UUIDv7 so IDs sort by creation time.
+func generateRequestID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic("Failed to generate request ID: " + err.Error())
+	}
+	b[6] = (b[6] & 0x0f) | 0x70
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
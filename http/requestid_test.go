@@ -0,0 +1,88 @@
+package http_test
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+var uuidV7 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestIDIsGeneratedOrReused(t *testing.T) {
+	app := http.New()
+	app.Use(http.RequestID(nil))
+	app.Get("/", func(ctx *http.Context) { ctx.Send(ctx.GetRequestID()) })
+
+	client := expresstest.New(t, app)
+	output := captureStdout(t, func() {
+		res := client.Get("/").Expect(200).Response()
+		if id := res.Header.Get("X-Request-ID"); !uuidV7.MatchString(id) || res.Text() != id {
+			t.Errorf("expected a generated UUIDv7 in the header and the handler, got %q and %q", id, res.Text())
+		}
+
+		client.Get("/").Header("X-Request-ID", "upstream-42").ExpectHeader("X-Request-ID", "upstream-42").ExpectBody("upstream-42")
+
+		res = client.Get("/").Header("X-Request-ID", "bad id\x7f").Response()
+		if id := res.Header.Get("X-Request-ID"); !uuidV7.MatchString(id) {
+			t.Errorf("invalid incoming ID should be replaced, got %q", id)
+		}
+		res = client.Get("/").Header("X-Request-ID", strings.Repeat("a", 129)).Response()
+		if id := res.Header.Get("X-Request-ID"); !uuidV7.MatchString(id) {
+			t.Errorf("oversized incoming ID should be replaced, got %q", id)
+		}
+	})
+
+	if !strings.Contains(output, "[upstream-42] GET / - 200") {
+		t.Errorf("expected the request ID in the access log:\n%s", output)
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	app := http.New()
+	app.Use(http.RequestID(&http.RequestIDOptions{
+		Header:    "X-Correlation-ID",
+		Generator: func() string { return "fixed" },
+	}))
+	app.Get("/", func(ctx *http.Context) { ctx.Send(ctx.GetRequestID()) })
+	app.Get("/fail", func(ctx *http.Context) { ctx.Error(errors.New("boom")) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").Expect(200).ExpectHeader("X-Correlation-ID", "fixed").ExpectHeader("X-Request-ID", "").ExpectBody("fixed")
+		client.Get("/fail").Expect(500).ExpectJSON(map[string]any{"error": "boom", "requestId": "fixed"})
+	})
+}
+
+func TestRequestIDIsForwardedByHTTPClient(t *testing.T) {
+	var forwarded string
+	downstream := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		forwarded = r.Header.Get("X-Request-ID")
+	}))
+	defer downstream.Close()
+
+	app := http.New()
+	app.Use(http.RequestID(nil))
+	app.Get("/", func(ctx *http.Context) {
+		res, err := ctx.HTTPClient().Get(downstream.URL)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		res.Body.Close()
+		ctx.Send("ok")
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").Header("X-Request-ID", "trace-1").Expect(200)
+	})
+	if forwarded != "trace-1" {
+		t.Errorf("downstream got request ID %q", forwarded)
+	}
+}
@@ -47,6 +47,13 @@ func (res *Response) AddHeader(key string, value interface{}) {
 	w.Header().Set(key, value.(string))
 }
 
+func (res *Response) HeadersSent() bool {
+	if w, ok := res.Writer.(interface{ Written() bool }); ok {
+		return w.Written()
+	}
+	return false
+}
+
 func (res *Response) Status(code int) *Response {
 	res.StatusCode = code
 	return res
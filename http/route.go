@@ -1,115 +1,87 @@
 package http
 
-import (
-	"fmt"
-)
-
 // TODO: Handle iterative routing parameters
 
 func (s *Server) AddRoute(path string, handler Handler, method []string) {
 	if validateRoute(path, handler) {
-		for _, m := range method {
-			if _, ok := s.Routes[m]; !ok {
-				s.Routes[m] = []Route{}
-			}
+		source := callerSource()
 
-			Params := []string{}
+		// parse once, every method shares the same pattern and params
+		Params := []string{}
 
-			if isParameterizedRoute(path) {
-				path, Params = getParameterizedRoute(path)
-			}
+		if isParameterizedRoute(path) {
+			path, Params = getParameterizedRoute(path)
+		}
 
-			fmt.Printf("🚀 [%s] %s Route loaded\n", m, path)
+		searchParams := getSearchParams(path)
+		path = removeQueryParams(path)
 
-			searchParams := getSearchParams(path)
-			path = removeQueryParams(path)
+		for _, m := range method {
+			if _, ok := s.Routes[m]; !ok {
+				s.Routes[m] = []Route{}
+			}
 
-			s.Routes[m] = append(s.Routes[m], Route{
+			s.insertRoute(m, Route{
 				Method:       method,
 				Path:         path,
 				Handler:      handler,
 				Params:       Params,
 				SearchParams: searchParams,
 				Middlewares:  append([]Middleware{}, s.Middlewares...),
+				source:       source,
+				builtIns:     s.builtIns,
 			})
-			s.Routes[m] = sortRoutesWithParamsLast(s.Routes[m])
 		}
 	}
 }
 
 func (s *Server) addRouteWithMiddleware(path string, handler Handler, method []string, middlewares ...Middleware) {
 	if validateRoute(path, handler) {
-		for _, m := range method {
-			if _, ok := s.Routes[m]; !ok {
-				s.Routes[m] = []Route{}
-			}
+		source := callerSource()
 
-			Params := []string{}
+		Params := []string{}
 
-			fmt.Printf("🚀 [%s] %s Route loaded\n", m, path)
-
-			if isParameterizedRoute(path) {
-				path, Params = getParameterizedRoute(path)
-			}
+		if isParameterizedRoute(path) {
+			path, Params = getParameterizedRoute(path)
+		}
 
-			searchParams := getSearchParams(path)
-			path = removeQueryParams(path)
+		searchParams := getSearchParams(path)
+		path = removeQueryParams(path)
 
-			allMiddlewares := s.Middlewares
+		for _, m := range method {
+			if _, ok := s.Routes[m]; !ok {
+				s.Routes[m] = []Route{}
+			}
 
-			s.Routes[m] = append(s.Routes[m], Route{
+			s.insertRoute(m, Route{
 				Method:       method,
 				Path:         path,
 				Handler:      handler,
 				Params:       Params,
 				SearchParams: searchParams,
-				Middlewares:  append(allMiddlewares, middlewares...),
+				Middlewares:  append(append([]Middleware{}, s.Middlewares...), middlewares...),
+				source:       source,
+				builtIns:     s.builtIns,
 			})
-			s.Routes[m] = sortRoutesWithParamsLast(s.Routes[m])
 		}
 	}
 }
 
+// AddRouteWithRouter registers the router's current routes under path. Unlike
+// UseRouter, routes added to the router afterwards are not picked up.
 func (s *Server) AddRouteWithRouter(path string, router *Router) {
 	if router == nil {
 		return
 	}
 
-	if path == "" || path[0] != '/' {
-		path = "/" + path
+	mount := routerMount{
+		server:      s,
+		prefix:      normalizeMountPrefix(path),
+		middlewares: append([]Middleware{}, s.Middlewares...),
+		builtIns:    s.builtIns,
 	}
-
 	for _, route := range router.routes {
-		if validateRoute(path, route.Handler) {
-			for _, m := range route.Method {
-				if _, ok := s.Routes[m]; !ok {
-					s.Routes[m] = []Route{}
-				}
-
-				Params := []string{}
-
-				fmt.Printf("🚀 [%s] %s Route loaded\n", m, path)
-
-				if isParameterizedRoute(path) {
-					path, Params = getParameterizedRoute(path)
-				}
-
-				searchParams := getSearchParams(path)
-				path = removeQueryParams(path)
-
-				middlewares := s.Middlewares
-
-				s.Routes[m] = append(s.Routes[m], Route{
-					Method:       route.Method,
-					Path:         path,
-					Handler:      route.Handler,
-					Params:       Params,
-					SearchParams: searchParams,
-					Middlewares:  append(middlewares, router.middlewares...),
-				})
-				s.Routes[m] = sortRoutesWithParamsLast(s.Routes[m])
-			}
-		}
+		mount.add(route)
 	}
 }
 
@@ -178,7 +150,7 @@ func (s *Server) Head(path string, handler Handler) *RouteChain {
 	return &RouteChain{
 		server: s,
 		path:   path,
-		method: []string{"GET"},
+		method: []string{"HEAD"},
 	}
 }
 
@@ -198,15 +170,21 @@ func (s *Server) UseRouter(path string, router *Router) {
 		return
 	}
 
-	if path == "" || path[0] != '/' {
-		path = "/" + path
-	}
+	// the router stays linked, routes added to it later are registered here too
+	router.attach(routerMount{
+		server:      s,
+		prefix:      normalizeMountPrefix(path),
+		middlewares: append([]Middleware{}, s.Middlewares...),
+		builtIns:    s.builtIns,
+	})
+}
 
-	for _, route := range router.routes {
-		if route.Path != "/" && route.Path[0] != '/' {
-			route.Path = "/" + route.Path
+func (s *Server) addMountedRoute(route Route) {
+	for _, m := range route.Method {
+		if _, ok := s.Routes[m]; !ok {
+			s.Routes[m] = []Route{}
 		}
-		fullPath := path + route.Path
-		s.addRouteWithMiddleware(fullPath, route.Handler, route.Method, route.Middlewares...)
+
+		s.insertRoute(m, route)
 	}
 }
@@ -3,6 +3,7 @@ package http
 type Router struct {
 	routes      []Route
 	middlewares []Middleware
+	mounts      []routerMount
 }
 
 func NewRouter() *Router {
@@ -25,14 +26,17 @@ func (r *Router) AddRoute(path string, handler Handler, method []string) {
 		searchParams := getSearchParams(path)
 		path = removeQueryParams(path)
 
-		r.routes = append(r.routes, Route{
+		route := Route{
 			Method:       method,
 			Path:         path,
 			Handler:      handler,
 			Params:       params,
 			SearchParams: searchParams,
 			Middlewares:  append([]Middleware{}, r.middlewares...),
-		})
+			source:       callerSource(),
+		}
+		r.routes = append(r.routes, route)
+		r.publish(route)
 	}
 }
 
@@ -108,17 +112,11 @@ func (r *Router) UseRouter(path string, router *Router) {
 		return
 	}
 
-	if path == "" || path[0] != '/' {
-		path = "/" + path
-	}
-
-	for _, route := range router.routes {
-		if route.Path != "/" && route.Path[0] != '/' {
-			route.Path = "/" + route.Path
-		}
-		fullPath := path + route.Path
-		r.addRouteWithMiddleware(fullPath, route.Handler, route.Method, route.Middlewares...)
-	}
+	router.attach(routerMount{
+		router:      r,
+		prefix:      normalizeMountPrefix(path),
+		middlewares: append([]Middleware{}, r.middlewares...),
+	})
 }
 
 func (r *Router) Use(middlewares ...Middleware) {
@@ -155,13 +153,16 @@ func (r *Router) addRouteWithMiddleware(path string, handler Handler, method []s
 		searchParams := getSearchParams(path)
 		path = removeQueryParams(path)
 
-		r.routes = append(r.routes, Route{
+		route := Route{
 			Method:       method,
 			Path:         path,
 			Handler:      handler,
 			Params:       params,
 			SearchParams: searchParams,
 			Middlewares:  append(append([]Middleware{}, r.middlewares...), middlewares...),
-		})
+			source:       callerSource(),
+		}
+		r.routes = append(r.routes, route)
+		r.publish(route)
 	}
 }
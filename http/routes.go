@@ -1,32 +1,14 @@
 package http
 
 import (
-	"fmt"
 	"net/http"
-	"regexp"
 )
 
 func (s *Server) GetParams(routePath, actualPath string) map[string]string {
-	params := make(map[string]string)
-
-	re := regexp.MustCompile(`\{([^\s/]+)\}`)
-	paramNames := re.FindAllStringSubmatch(routePath, -1)
-
-	pattern := re.ReplaceAllString(routePath, `([^/]+)`)
-	pattern = "^" + pattern + "$"
-	valueRe := regexp.MustCompile(pattern)
-
-	matches := valueRe.FindStringSubmatch(actualPath)
-	if matches == nil {
-		return params
-	}
-
-	for i, name := range paramNames {
-		if len(matches) > i+1 {
-			params[name[1]] = matches[i+1]
-		}
+	params, _, ok := compileRoute(routePath, false).match(normalizeRoutePath(actualPath))
+	if !ok {
+		return make(map[string]string)
 	}
-
 	return params
 }
 
@@ -54,6 +36,7 @@ func chainMiddlewares(middlewares []Middleware, handler Handler) Handler {
 		var exec func(index int)
 		exec = func(index int) {
 			if index < len(middlewares) {
+				ctx.chain, ctx.handler = middlewares[index:], handler
 				middlewares[index](ctx, func() {
 					exec(index + 1)
 				})
@@ -66,79 +49,119 @@ func chainMiddlewares(middlewares []Middleware, handler Handler) Handler {
 }
 
 func (s *Server) HandleRoutes(w http.ResponseWriter, r *http.Request) {
-	for _, route := range s.Routes[r.Method] {
-
-		re := regexp.MustCompile(`\{[^\s/]+\}`)
-		path := re.ReplaceAllString(route.Path, "[^/]+")
+	if s.serveHosts(w, r) {
+		return
+	}
+	s.serve(w, r, nil)
+}
 
-		if path[0] != '/' {
-			path = `/` + path
+// serve matches the request against the server's routes. Mounted applications
+// are tried after static routes and before parameterized ones, so a catch-all
+// like "/:page" does not swallow a mounted prefix. parent is set when the
+// server is a mounted sub-application.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, parent *Context) {
+	appsTried := false
+	for _, route := range s.Routes[r.Method] {
+		if !appsTried && len(route.Params) > 0 {
+			appsTried = true
+			if s.serveApps(w, r, parent) {
+				return
+			}
 		}
 
-		urlPath := r.URL.Path
-		if urlPath[0] != '/' {
-			urlPath = `/` + urlPath
+		if matchRoute(route.Path, r.URL.Path) {
+			ctx := s.newContext(w, r, s.GetParams(route.Path, r.URL.Path), parent)
+			ctx.setMount(route.mountPath, ctx.BaseURL()+mountedBase(route.mountPath, r.URL.Path))
+			ctx.Request.AdditionalFields["route"] = route.info()
+			chainMiddlewares(ownMiddlewares(route.chain(), route.builtIns, parent), route.Handler)(ctx)
+			return
 		}
+	}
 
-		if urlPath[len(urlPath)-1] == '/' {
-			urlPath = urlPath[:len(urlPath)-1]
-		}
+	if !appsTried && s.serveApps(w, r, parent) {
+		return
+	}
 
-		if path[len(path)-1] == '/' {
-			path = path[:len(path)-1]
-		}
+	s.notFound(w, r, parent)
+}
 
-		re = regexp.MustCompile(fmt.Sprintf(`^%s$`, path))
+// matchRoute reports whether the request path matches the route, constraints
+// included, so a failed constraint falls through to the next route.
+func matchRoute(routePath string, urlPath string) bool {
+	_, _, ok := compileRoute(routePath, false).match(normalizeRoutePath(urlPath))
+	return ok
+}
 
-		if re.MatchString(urlPath) {
+// newContext builds the per-request context. A mounted sub-application shares
+// the parent's writer, locals and request fields (request ID, params of the mount prefix...).
+func (s *Server) newContext(w http.ResponseWriter, r *http.Request, params map[string]string, parent *Context) *Context {
+	fields := map[string]any{}
+	locals := make(map[string]any)
+	var writer http.ResponseWriter
 
-			s.Request.Method = r.Method
-			s.Request.Url = r.URL.String()
-			s.Request.Headers = s.GetHeaders(r)
-			s.Request.r = r
-			s.Request.AdditionalFields = map[string]any{
-				"params": s.GetParams(route.Path, r.URL.Path),
-			}
+	if parent != nil {
+		for key, value := range parent.Request.AdditionalFields {
+			fields[key] = value
+		}
+		merged := make(map[string]string)
+		for key, value := range parent.GetParams() {
+			merged[key] = value
+		}
+		for key, value := range params {
+			merged[key] = value
+		}
+		params = merged
+		locals = parent.Locals
+		writer = w
+	} else {
+		writer = newResponseWriter(w)
+	}
+	fields["params"] = params
+
+	req := &Request{
+		r:                r,
+		Method:           r.Method,
+		Url:              r.URL.String(),
+		Headers:          s.GetHeaders(r),
+		AdditionalFields: fields,
+	}
 
-			s.Response.Writer = w
-			s.Response.StatusCode = 0
-			s.Response.Headers = s.GetBasicResponseHeaders(r.Method)
+	res := &Response{
+		Writer:  writer,
+		Headers: s.GetBasicResponseHeaders(r.Method),
+	}
+	if parent != nil {
+		res.StatusCode = parent.Response.StatusCode
+	}
 
-			ctx := &Context{
-				Request:  s.Request,
-				Response: s.Response,
-			}
+	ctx := &Context{
+		Request:  req,
+		Response: res,
+		Locals:   locals,
+		server:   s,
+	}
+	if parent != nil {
+		ctx.root = parent.root
+		if ctx.root == nil {
+			ctx.root = parent
+		}
+	}
+	return ctx
+}
 
-			middlewares := route.Middlewares
-			handler := route.Handler
-
-			handlerWithErrorSafety := func(ctx *Context) {
-				defer func() {
-					if errRecovery := recover(); errRecovery != nil {
-						var err error
-						switch e := errRecovery.(type) {
-						case error:
-							err = e
-						default:
-							err = fmt.Errorf("%v", e)
-						}
-						if s.ErrorHandler != nil {
-							s.ErrorHandler(ctx, err)
-						} else {
-							ctx.Response.StatusCode = http.StatusInternalServerError
-							ctx.Response.Writer.WriteHeader(http.StatusInternalServerError)
-							ctx.Response.Writer.Write([]byte(fmt.Sprintf("Internal Server Error: %v", err)))
-						}
-					}
-				}()
-				handler(ctx)
-			}
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request, parent *Context) {
+	if parent == nil && s.serveRouteTable(w, r) {
+		return
+	}
 
-			chainMiddlewares(middlewares, handlerWithErrorSafety)(ctx)
-			return
-		}
+	handler := s.notFoundHandler()
+	if handler == nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("404 Not Found"))
+		return
 	}
 
-	w.WriteHeader(http.StatusNotFound)
-	w.Write([]byte("404 Not Found"))
+	ctx := s.newContext(w, r, map[string]string{}, parent)
+	ctx.Response.StatusCode = http.StatusNotFound
+	chainMiddlewares(ownMiddlewares(append([]Middleware{}, s.Middlewares...), s.builtIns, parent), handler)(ctx)
 }
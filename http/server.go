@@ -1,7 +1,9 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"os"
 	"strconv"
 )
 
@@ -25,8 +27,26 @@ func (s *Server) Listen(port int, callback func(int, error)) {
 		s.HandleRoutes(w, r)
 	})
 
+	server := &http.Server{
+		Addr:              addr + ":" + strconv.Itoa(port),
+		Handler:           mux,
+		ReadTimeout:       s.ReadTimeout,
+		ReadHeaderTimeout: s.ReadHeaderTimeout,
+		WriteTimeout:      s.WriteTimeout,
+		IdleTimeout:       s.IdleTimeout,
+		MaxHeaderBytes:    s.MaxHeaderBytes,
+	}
+
+	if conflicts := s.RouteConflicts(); s.StrictRoutes && len(conflicts) > 0 {
+		panic(conflicts[0].Error())
+	}
+
+	if s.LogRoutes {
+		s.PrintRoutes(os.Stdout)
+	}
+
 	go func() {
-		err := http.ListenAndServe(addr+":"+strconv.Itoa(port), mux)
+		err := server.ListenAndServe()
 		if err != nil && callback != nil {
 			callback(port, err)
 		}
@@ -40,14 +60,23 @@ func (s *Server) Listen(port int, callback func(int, error)) {
 }
 
 func basicErrorHandler(ctx *Context, err error) {
-	ctx.Response.StatusCode = http.StatusOK
-	ctx.Response.Json(map[string]any{
+	ctx.Response.StatusCode = http.StatusInternalServerError
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		ctx.Response.StatusCode = httpErr.Status
+		err = httpErr
+	}
+	body := map[string]any{
 		"error": err.Error(),
-	})
+	}
+	if id := ctx.GetRequestID(); id != "" {
+		body["requestId"] = id
+	}
+	ctx.Response.Json(body)
 }
 
 func CreateServer() *Server {
-	return &Server{
+	s := &Server{
 		Routes:       make(map[string][]Route),
 		ErrorHandler: basicErrorHandler,
 		Request: &Request{
@@ -58,16 +87,41 @@ func CreateServer() *Server {
 		Response: &Response{
 			Headers: make(map[string]string),
 		},
-		Middlewares: []Middleware{
-			Logs(&LogOptions{
-				Enable: true,
-				Format: "{{.Method}} {{.Path}} - {{.StatusCode}}",
-			}),
-			uploadFiles("uploads"),
-		},
+		Locals: make(map[string]any),
+		views:  newViewSet(nil),
+	}
+	// Logs wraps Recover so panicking requests are logged with the status the error handler sent
+	s.Middlewares = []Middleware{
+		Logs(&LogOptions{
+			Enable: true,
+			Format: "{{.Method}} {{.Path}} - {{.StatusCode}}",
+		}),
+		s.builtInRecover(),
+		uploadFiles(defaultUploadDir),
 	}
+	s.builtIns = len(s.Middlewares)
+	return s
 }
 
 func (s *Server) SetErrorHandler(handler ErrorHandlerType) {
 	s.ErrorHandler = handler
+	s.errorHandlerSet = true
+}
+
+func (s *Server) SetServerOptions(options *ServerOptions) {
+	if options == nil {
+		return
+	}
+	if options.Address != "" {
+		s.Address = options.Address
+	}
+	s.Dev = options.Dev
+	s.LogRoutes = options.LogRoutes
+	s.StrictRoutes = options.StrictRoutes
+	s.WebSocket = options.WebSocket
+	s.ReadTimeout = options.ReadTimeout
+	s.ReadHeaderTimeout = options.ReadHeaderTimeout
+	s.WriteTimeout = options.WriteTimeout
+	s.IdleTimeout = options.IdleTimeout
+	s.MaxHeaderBytes = options.MaxHeaderBytes
 }
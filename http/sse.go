@@ -0,0 +1,394 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SSEOptions struct {
+	Heartbeat time.Duration
+	Retry     time.Duration
+}
+
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  any
+	Retry time.Duration
+}
+
+type SSEStream struct {
+	LastEventID string
+	writer      http.ResponseWriter
+	controller  *http.ResponseController
+	context     context.Context
+	mu          sync.Mutex
+	closed      bool
+}
+
+var ErrSSEClosed = errors.New("sse stream closed")
+
+func (ctx *Context) SSE(handler func(stream *SSEStream)) {
+	ctx.SSEWithOptions(nil, handler)
+}
+
+func (ctx *Context) SSEWithOptions(options *SSEOptions, handler func(stream *SSEStream)) {
+	heartbeat := 15 * time.Second
+	var retry time.Duration
+
+	if options != nil {
+		if options.Heartbeat != 0 {
+			heartbeat = options.Heartbeat
+		}
+		retry = options.Retry
+	}
+
+	w := ctx.Response.Writer
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	header.Del("Content-Length")
+
+	lastEventID := ctx.Request.r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Request.r.URL.Query().Get("lastEventId")
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+
+	stream := &SSEStream{
+		LastEventID: lastEventID,
+		writer:      w,
+		controller:  http.NewResponseController(w),
+		context:     streamCtx,
+	}
+
+	ctx.Response.StatusCode = http.StatusOK
+	w.WriteHeader(http.StatusOK)
+	if retry > 0 {
+		stream.write("retry: " + strconv.FormatInt(retry.Milliseconds(), 10) + "\n\n")
+	} else {
+		stream.flush()
+	}
+
+	if heartbeat > 0 {
+		go func() {
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-streamCtx.Done():
+					return
+				case <-ticker.C:
+					if stream.Comment("heartbeat") != nil {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	handler(stream)
+
+	stream.mu.Lock()
+	stream.closed = true
+	stream.mu.Unlock()
+}
+
+// Done is closed when the client disconnects or the handler returns.
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.context.Done()
+}
+
+func (s *SSEStream) Send(event SSEEvent) error {
+	data, err := encodeSSEData(event.Data)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: " + sanitizeSSEField(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		b.WriteString("event: " + sanitizeSSEField(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+func (s *SSEStream) Event(name string, data any) error {
+	return s.Send(SSEEvent{Event: name, Data: data})
+}
+
+func (s *SSEStream) Data(data any) error {
+	return s.Send(SSEEvent{Data: data})
+}
+
+func (s *SSEStream) Comment(comment string) error {
+	return s.write(": " + sanitizeSSEField(comment) + "\n\n")
+}
+
+func (s *SSEStream) write(message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.context.Err() != nil {
+		return ErrSSEClosed
+	}
+	if _, err := s.writer.Write([]byte(message)); err != nil {
+		s.closed = true
+		return err
+	}
+	return s.controller.Flush()
+}
+
+func (s *SSEStream) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controller.Flush()
+}
+
+func encodeSSEData(data any) (string, error) {
+	switch d := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	default:
+		jsonBytes, err := json.Marshal(d)
+		if err != nil {
+			return "", err
+		}
+		return string(jsonBytes), nil
+	}
+}
+
+// sanitizeSSEField keeps single-line fields from injecting extra lines into the stream.
+func sanitizeSSEField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+type SSEHubOptions struct {
+	Buffer  int
+	History int
+}
+
+// SSEHub fans events out to every subscriber of a topic. It keeps a short
+// history per topic so reconnecting clients can resume from Last-Event-ID.
+// Events of all topics share one sequence, so a client of several topics
+// resumes each of them from the same point.
+type SSEHub struct {
+	mu          sync.RWMutex
+	topics      map[string]map[*SSESubscription]struct{}
+	history     map[string][]sseHubEvent
+	buffer      int
+	historySize int
+	sequence    uint64
+}
+
+type sseHubEvent struct {
+	sequence uint64
+	event    SSEEvent
+}
+
+type SSESubscription struct {
+	hub    *SSEHub
+	topics []string
+	events chan SSEEvent
+	once   sync.Once
+}
+
+func NewSSEHub(options *SSEHubOptions) *SSEHub {
+	hub := &SSEHub{
+		topics:      make(map[string]map[*SSESubscription]struct{}),
+		history:     make(map[string][]sseHubEvent),
+		buffer:      16,
+		historySize: 100,
+	}
+	if options != nil {
+		if options.Buffer > 0 {
+			hub.buffer = options.Buffer
+		}
+		if options.History != 0 {
+			hub.historySize = options.History
+		}
+	}
+	return hub
+}
+
+// Publish assigns an ID to events that have none. Subscribers whose buffer is
+// full miss the event instead of blocking the publisher.
+func (h *SSEHub) Publish(topic string, event SSEEvent) {
+	h.mu.Lock()
+	h.sequence++
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(h.sequence, 10)
+	}
+	if h.historySize > 0 {
+		history := append(h.history[topic], sseHubEvent{sequence: h.sequence, event: event})
+		if len(history) > h.historySize {
+			history = history[len(history)-h.historySize:]
+		}
+		h.history[topic] = history
+	}
+	subscribers := make([]*SSESubscription, 0, len(h.topics[topic]))
+	for sub := range h.topics[topic] {
+		subscribers = append(subscribers, sub)
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subscribers {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+func (h *SSEHub) Subscribe(topics ...string) *SSESubscription {
+	sub, _ := h.subscribe(topics, "")
+	return sub
+}
+
+// subscribe registers the subscription and collects the events to replay under
+// the same lock, so each event is either replayed or delivered, never both.
+func (h *SSEHub) subscribe(topics []string, lastEventID string) (*SSESubscription, []SSEEvent) {
+	sub := &SSESubscription{
+		hub:    h,
+		topics: topics,
+		events: make(chan SSEEvent, h.buffer),
+	}
+
+	h.mu.Lock()
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*SSESubscription]struct{})
+		}
+		h.topics[topic][sub] = struct{}{}
+	}
+	replay := h.since(topics, lastEventID)
+	h.mu.Unlock()
+
+	return sub, replay
+}
+
+// Since returns the buffered events of a topic published after lastEventID,
+// which may be the ID of an event of any topic of the hub.
+func (h *SSEHub) Since(topic string, lastEventID string) []SSEEvent {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.since([]string{topic}, lastEventID)
+}
+
+// since returns the buffered events of topics published after lastEventID, in
+// the order they were published.
+func (h *SSEHub) since(topics []string, lastEventID string) []SSEEvent {
+	if lastEventID == "" {
+		return nil
+	}
+	last, ok := h.sequenceOf(lastEventID)
+	if !ok {
+		return nil
+	}
+
+	missed := []sseHubEvent{}
+	seen := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		if seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		for _, entry := range h.history[topic] {
+			if entry.sequence > last {
+				missed = append(missed, entry)
+			}
+		}
+	}
+	sort.Slice(missed, func(i, j int) bool { return missed[i].sequence < missed[j].sequence })
+
+	events := make([]SSEEvent, len(missed))
+	for i, entry := range missed {
+		events[i] = entry.event
+	}
+	return events
+}
+
+func (h *SSEHub) sequenceOf(eventID string) (uint64, bool) {
+	for _, history := range h.history {
+		for _, entry := range history {
+			if entry.event.ID == eventID {
+				return entry.sequence, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (h *SSEHub) Subscribers(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.topics[topic])
+}
+
+// Serve streams the given topics to the client until it disconnects,
+// replaying missed events first when the client sends Last-Event-ID.
+func (h *SSEHub) Serve(ctx *Context, topics ...string) {
+	ctx.SSE(func(stream *SSEStream) {
+		sub, replay := h.subscribe(topics, stream.LastEventID)
+		defer sub.Close()
+
+		for _, event := range replay {
+			if stream.Send(event) != nil {
+				return
+			}
+		}
+
+		for {
+			select {
+			case <-stream.Done():
+				return
+			case event := <-sub.Events():
+				if stream.Send(event) != nil {
+					return
+				}
+			}
+		}
+	})
+}
+
+func (s *SSESubscription) Events() <-chan SSEEvent {
+	return s.events
+}
+
+func (s *SSESubscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		for _, topic := range s.topics {
+			delete(s.hub.topics[topic], s)
+			if len(s.hub.topics[topic]) == 0 {
+				delete(s.hub.topics, topic)
+			}
+		}
+		s.hub.mu.Unlock()
+	})
+}
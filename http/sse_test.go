@@ -0,0 +1,223 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/http"
+)
+
+type sseMessage struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSE connects to url and returns the first n events the server sends.
+func readSSE(url string, lastEventID string, n int) ([]sseMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		return nil, fmt.Errorf("unexpected Content-Type %q", ct)
+	}
+
+	messages := []sseMessage{}
+	current := sseMessage{}
+	scanner := bufio.NewScanner(res.Body)
+	for len(messages) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current != (sseMessage{}) {
+				messages = append(messages, current)
+			}
+			current = sseMessage{}
+		case strings.HasPrefix(line, "id: "):
+			current.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			current.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	if len(messages) < n {
+		return messages, fmt.Errorf("stream ended after %d of %d events: %v", len(messages), n, scanner.Err())
+	}
+	return messages, nil
+}
+
+func waitForSubscribers(t *testing.T, hub *http.SSEHub, topic string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers(topic) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d subscribers, got %d", n, hub.Subscribers(topic))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSSEStreamsEvents(t *testing.T) {
+	app := http.New()
+	app.Get("/events", func(ctx *http.Context) {
+		ctx.SSE(func(stream *http.SSEStream) {
+			stream.Send(http.SSEEvent{ID: "7", Event: "greeting", Data: "hello"})
+			stream.Data(map[string]any{"n": 1})
+		})
+	})
+	captureStdout(t, func() {
+		// Close waits for the handlers, so their access lines are captured too
+		server := httptest.NewServer(app)
+		defer server.Close()
+
+		messages, err := readSSE(server.URL+"/events", "", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if messages[0] != (sseMessage{id: "7", event: "greeting", data: "hello"}) {
+			t.Errorf("unexpected first event %+v", messages[0])
+		}
+		if messages[1].data != `{"n":1}` {
+			t.Errorf("unexpected second event %+v", messages[1])
+		}
+	})
+}
+
+func TestSSEHubReplaysMissedEvents(t *testing.T) {
+	hub := http.NewSSEHub(nil)
+	for i := 0; i < 3; i++ {
+		hub.Publish("news", http.SSEEvent{Data: "old " + strconv.Itoa(i)})
+	}
+	app := http.New()
+	app.Get("/news", func(ctx *http.Context) { hub.Serve(ctx, "news") })
+	captureStdout(t, func() {
+		server := httptest.NewServer(app)
+		defer server.Close()
+
+		var messages []sseMessage
+		var err error
+		done := make(chan struct{})
+		go func() {
+			messages, err = readSSE(server.URL+"/news", "1", 3)
+			close(done)
+		}()
+		waitForSubscribers(t, hub, "news", 1)
+		hub.Publish("news", http.SSEEvent{Data: "live"})
+
+		<-done
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, m := range messages {
+			got = append(got, m.id+"="+m.data)
+		}
+		if strings.Join(got, ",") != "2=old 1,3=old 2,4=live" {
+			t.Errorf("unexpected events %v", got)
+		}
+		waitForSubscribers(t, hub, "news", 0)
+	})
+}
+
+func TestSSEHubResumesAllTopicsFromOneEventID(t *testing.T) {
+	hub := http.NewSSEHub(nil)
+	hub.Publish("orders", http.SSEEvent{Data: "order 1"})
+	hub.Publish("payments", http.SSEEvent{Data: "payment 1"})
+	hub.Publish("orders", http.SSEEvent{Data: "order 2"})
+	hub.Publish("payments", http.SSEEvent{Data: "payment 2"})
+	hub.Publish("news", http.SSEEvent{Data: "unrelated"})
+
+	app := http.New()
+	app.Get("/feed", func(ctx *http.Context) { hub.Serve(ctx, "orders", "payments") })
+	captureStdout(t, func() {
+		server := httptest.NewServer(app)
+		defer server.Close()
+
+		// the client last saw "payment 1", an event of the other topic than "order 2"
+		messages, err := readSSE(server.URL+"/feed", "2", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, m := range messages {
+			got = append(got, m.id+"="+m.data)
+		}
+		if strings.Join(got, ",") != "3=order 2,4=payment 2" {
+			t.Errorf("unexpected events %v", got)
+		}
+	})
+
+	if events := hub.Since("orders", "4"); len(events) != 0 {
+		t.Errorf("expected nothing after the last event, got %v", events)
+	}
+}
+
+// a reconnecting client must not receive an event twice when it is published
+// while the client subscribes
+func TestSSEHubReplayDoesNotDuplicateConcurrentEvents(t *testing.T) {
+	hub := http.NewSSEHub(&http.SSEHubOptions{Buffer: 1 << 16, History: 1 << 16})
+	app := http.New()
+	app.Get("/ticks", func(ctx *http.Context) { hub.Serve(ctx, "ticks") })
+
+	var published atomic.Int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for id := int64(1); ; id++ {
+			select {
+			case <-stop:
+				return
+			default:
+				hub.Publish("ticks", http.SSEEvent{ID: strconv.FormatInt(id, 10), Data: "tick"})
+				published.Store(id)
+				runtime.Gosched()
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	captureStdout(t, func() {
+		server := httptest.NewServer(app)
+		defer server.Close()
+
+		for i := 0; i < 20; i++ {
+			last := published.Load()
+			messages, err := readSSE(server.URL+"/ticks", strconv.FormatInt(last, 10), 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range messages {
+				id, _ := strconv.ParseInt(m.id, 10, 64)
+				if id <= last {
+					t.Fatalf("event %d received after %d", id, last)
+				}
+				last = id
+			}
+		}
+	})
+}
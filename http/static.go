@@ -1,53 +1,389 @@
 package http
 
 import (
-	"fmt"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
 	"net/http"
+	"net/url"
+	"os"
 	"path"
-	"runtime"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-func (s *Server) Static(prefix string, dir string) {
+const (
+	DotfilesIgnore = "ignore"
+	DotfilesAllow  = "allow"
+	DotfilesDeny   = "deny"
+)
+
+type StaticOptions struct {
+	FS          fs.FS
+	Index       []string
+	Browse      bool
+	MaxAge      time.Duration
+	Immutable   bool
+	DisableETag bool
+	Dotfiles    string
+	SPA         bool
+}
+
+type staticHandler struct {
+	fsys    fs.FS
+	disk    fs.FS
+	options StaticOptions
+	etagMu  sync.Mutex
+	etags   map[string]staticETag
+}
+
+type staticETag struct {
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+func (s *Server) Static(prefix string, root string, options *StaticOptions) {
 	if prefix == "" || prefix[0] != '/' {
 		prefix = "/" + prefix
 	}
 	routePrefix := strings.TrimRight(prefix, "/")
 
-	if dir == "" {
+	handler := newStaticHandler(root, options)
+
+	s.AddRoute(routePrefix+"/*filepath", handler.serve, []string{"GET", "HEAD"})
+}
+
+func newStaticHandler(root string, options *StaticOptions) *staticHandler {
+	h := &staticHandler{
+		etags: make(map[string]staticETag),
+	}
+	if options != nil {
+		h.options = *options
+	}
+	if len(h.options.Index) == 0 {
+		h.options.Index = []string{"index.html"}
+	}
+	if h.options.Dotfiles == "" {
+		h.options.Dotfiles = DotfilesIgnore
+	}
+
+	if root != "" {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			panic("Failed to resolve static directory: " + err.Error())
+		}
+		h.disk = dirFS(absRoot)
+	}
+
+	h.fsys = h.disk
+	if h.options.FS != nil {
+		h.fsys = h.options.FS
+		if root != "" && root != "." {
+			sub, err := fs.Sub(h.options.FS, strings.Trim(path.Clean(filepath.ToSlash(root)), "/"))
+			if err != nil {
+				panic("Failed to resolve static directory: " + err.Error())
+			}
+			h.fsys = sub
+		}
+	}
+
+	if h.fsys == nil {
 		panic("Directory cannot be empty for static file server")
 	}
 
-	_, b, _, _ := runtime.Caller(0)
-	baseDir := path.Join(path.Dir(b), "../")
-	staticDir := path.Clean(path.Join(baseDir, dir))
+	return h
+}
 
-	s.Get(routePrefix, func(ctx *Context) {
-		http.Redirect(ctx.Response.Writer, ctx.Request.r, routePrefix+"/", http.StatusMovedPermanently)
-	})
+// filesystem reads straight from disk in dev mode so edits show up without a rebuild.
+func (h *staticHandler) filesystem(ctx *Context) fs.FS {
+	if h.disk != nil && ctx.server != nil && ctx.server.isDev() {
+		return h.disk
+	}
+	return h.fsys
+}
 
-	s.Get(routePrefix+"/", func(ctx *Context) {
-		http.StripPrefix(routePrefix+"/", http.FileServer(http.Dir(staticDir))).ServeHTTP(ctx.Response.Writer, ctx.Request.r)
-	})
+func (h *staticHandler) serve(ctx *Context) {
+	fsys := h.filesystem(ctx)
+	w := ctx.Response.Writer
+	r := ctx.Request.r
+
+	name := strings.TrimPrefix(path.Clean("/"+ctx.GetParam("filepath")), "/")
+	if name == "" {
+		name = "."
+	}
 
-	// TODO: Handle file paths with parameters
-	s.Get(routePrefix+"/:filepath", func(ctx *Context) {
-		filePath := ctx.GetParam("filepath")
-		fmt.Println("Requested file path:", filePath)
-		if filePath == "" {
-			http.NotFound(ctx.Response.Writer, ctx.Request.r)
+	if hasDotfile(name) {
+		switch h.options.Dotfiles {
+		case DotfilesDeny:
+			http.Error(w, "403 Forbidden", http.StatusForbidden)
+			return
+		case DotfilesIgnore:
+			h.notFound(ctx, fsys, name)
+			return
+		}
+	}
+
+	stat, err := fs.Stat(fsys, name)
+	if err != nil {
+		h.notFound(ctx, fsys, name)
+		return
+	}
+
+	if stat.IsDir() {
+		// relative links inside an index page only resolve against a trailing slash
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			target := r.URL.Path + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
 			return
 		}
 
-		cleanPath := path.Clean(filePath)
-		if strings.HasPrefix(cleanPath, "..") || path.IsAbs(cleanPath) {
-			http.Error(ctx.Response.Writer, "Invalid file path", http.StatusBadRequest)
+		for _, index := range h.options.Index {
+			indexName := path.Join(name, index)
+			if indexStat, err := fs.Stat(fsys, indexName); err == nil && !indexStat.IsDir() {
+				h.serveFile(ctx, fsys, indexName, indexStat)
+				return
+			}
+		}
+
+		if h.options.Browse {
+			h.listDirectory(ctx, fsys, name)
+			return
+		}
+
+		h.notFound(ctx, fsys, name)
+		return
+	}
+
+	h.serveFile(ctx, fsys, name, stat)
+}
+
+func (h *staticHandler) notFound(ctx *Context, fsys fs.FS, name string) {
+	// SPA fallback only applies to route-like paths, missing assets should still 404
+	if h.options.SPA && path.Ext(name) == "" {
+		index := h.options.Index[0]
+		if stat, err := fs.Stat(fsys, index); err == nil && !stat.IsDir() {
+			h.serveFile(ctx, fsys, index, stat)
 			return
 		}
+	}
+	http.NotFound(ctx.Response.Writer, ctx.Request.r)
+}
+
+func (h *staticHandler) serveFile(ctx *Context, fsys fs.FS, name string, stat fs.FileInfo) {
+	w := ctx.Response.Writer
+	r := ctx.Request.r
+
+	h.setCacheControl(w.Header())
+
+	if h.servePrecompressed(w, r, fsys, name) {
+		return
+	}
+
+	content, err := openSeekable(fsys, name)
+	if err != nil {
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	if !h.options.DisableETag {
+		if etag, err := h.etag(name, stat, content); err == nil {
+			w.Header().Set("ETag", etag)
+		}
+	}
 
-		fullPath := path.Join(staticDir, cleanPath)
-		fmt.Println("Serving static file:", fullPath)
-		http.ServeFile(ctx.Response.Writer, ctx.Request.r, fullPath)
+	http.ServeContent(w, r, stat.Name(), stat.ModTime(), content)
+}
+
+func (h *staticHandler) servePrecompressed(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) bool {
+	addVary(w.Header(), "Accept-Encoding")
+
+	if r.Header.Get("Range") != "" {
+		return false
+	}
+
+	weights := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	for _, candidate := range []struct{ encoding, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if weight, ok := weights[candidate.encoding]; !ok || weight <= 0 {
+			continue
+		}
+
+		stat, err := fs.Stat(fsys, name+candidate.ext)
+		if err != nil || stat.IsDir() {
+			continue
+		}
+		content, err := openSeekable(fsys, name+candidate.ext)
+		if err != nil {
+			continue
+		}
+
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Encoding", candidate.encoding)
+		if !h.options.DisableETag {
+			if etag, err := h.etag(name+candidate.ext, stat, content); err == nil {
+				w.Header().Set("ETag", etag)
+			}
+		}
+
+		http.ServeContent(w, r, path.Base(name), stat.ModTime(), content)
+		content.Close()
+		return true
+	}
+	return false
+}
+
+type readSeekCloser interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// openSeekable returns a seekable file so http.ServeContent can answer Range requests;
+// files from an fs.FS that cannot seek are buffered in memory.
+func openSeekable(fsys fs.FS, name string) (readSeekCloser, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := file.(readSeekCloser); ok {
+		return rs, nil
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return nopSeekCloser{bytes.NewReader(data)}, nil
+}
+
+type nopSeekCloser struct {
+	*bytes.Reader
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+// etag hashes the file content; results are cached until the size or modification time changes.
+func (h *staticHandler) etag(name string, stat fs.FileInfo, content io.ReadSeeker) (string, error) {
+	h.etagMu.Lock()
+	cached, ok := h.etags[name]
+	h.etagMu.Unlock()
+	if ok && cached.size == stat.Size() && cached.modTime.Equal(stat.ModTime()) {
+		return cached.value, nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	value := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+
+	h.etagMu.Lock()
+	h.etags[name] = staticETag{modTime: stat.ModTime(), size: stat.Size(), value: value}
+	h.etagMu.Unlock()
+
+	return value, nil
+}
+
+func (h *staticHandler) setCacheControl(header http.Header) {
+	cacheControl := "public, max-age=" + strconv.Itoa(int(h.options.MaxAge.Seconds()))
+	if h.options.Immutable {
+		cacheControl += ", immutable"
+	}
+	header.Set("Cache-Control", cacheControl)
+}
+
+func (h *staticHandler) listDirectory(ctx *Context, fsys fs.FS, name string) {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		http.Error(ctx.Response.Writer, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
 	})
 
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Index of /")
+	b.WriteString(html.EscapeString(strings.TrimPrefix(name, ".")))
+	b.WriteString("</title></head><body><ul>\n")
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if strings.HasPrefix(entryName, ".") && h.options.Dotfiles != DotfilesAllow {
+			continue
+		}
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		link := url.URL{Path: entryName}
+		b.WriteString("<li><a href=\"" + html.EscapeString(link.String()) + "\">" + html.EscapeString(entryName) + "</a></li>\n")
+	}
+	b.WriteString("</ul></body></html>\n")
+
+	w := ctx.Response.Writer
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
+
+func hasDotfile(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if len(segment) > 1 && segment[0] == '.' {
+			return true
+		}
+	}
+	return false
+}
+
+// dirFS is os.DirFS confined to its directory: a path whose symlinks resolve
+// outside of it is reported as missing.
+type dirFS string
+
+func (dir dirFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	root, err := filepath.EvalSymlinks(string(dir))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: unwrapPathError(err)}
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: unwrapPathError(err)}
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return os.Open(resolved)
+}
+
+// unwrapPathError keeps the resolved disk path out of errors.
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
 }
@@ -0,0 +1,185 @@
+package http_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+// writeFiles creates the files under a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestStaticServesFilesWithValidators(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.js":          "console.log(1)",
+		"docs/index.html": "<h1>docs</h1>",
+	})
+	app := http.New()
+	app.Static("/assets", dir, &http.StaticOptions{MaxAge: time.Hour, Immutable: true})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		res := client.Get("/assets/app.js").
+			Expect(200).
+			ExpectHeader("Cache-Control", "public, max-age=3600, immutable").
+			ExpectBody("console.log(1)").
+			Response()
+		if !strings.Contains(res.Header.Get("Content-Type"), "javascript") {
+			t.Errorf("unexpected Content-Type %q", res.Header.Get("Content-Type"))
+		}
+		etag := res.Header.Get("ETag")
+		if etag == "" || res.Header.Get("Last-Modified") == "" {
+			t.Fatalf("expected ETag and Last-Modified, got %v", res.Header)
+		}
+
+		client.Get("/assets/app.js").Header("If-None-Match", etag).Expect(304)
+		client.Get("/assets/app.js").Header("Range", "bytes=0-6").Expect(206).ExpectBody("console")
+		client.Get("/assets/docs").Expect(301).ExpectHeader("Location", "/assets/docs/")
+		client.Get("/assets/docs/").Expect(200).ExpectBody("<h1>docs</h1>")
+		client.Get("/assets/missing.js").Expect(404)
+		client.Get("/assets/../static_test.go").Expect(404)
+	})
+}
+
+func TestStaticDotfilesAndBrowse(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".env":        "SECRET=1",
+		"files/a.txt": "a",
+		"files/.b":    "b",
+	})
+	app := http.New()
+	app.Static("/ignore", dir, &http.StaticOptions{Browse: true})
+	app.Static("/deny", dir, &http.StaticOptions{Dotfiles: http.DotfilesDeny})
+	app.Static("/allow", dir, &http.StaticOptions{Dotfiles: http.DotfilesAllow})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/ignore/.env").Expect(404)
+		client.Get("/deny/.env").Expect(403)
+		client.Get("/allow/.env").Expect(200).ExpectBody("SECRET=1")
+
+		res := client.Get("/ignore/files/").Expect(200).ExpectHeader("Content-Type", "text/html; charset=utf-8").Response()
+		if !strings.Contains(res.Text(), `<a href="a.txt">a.txt</a>`) || strings.Contains(res.Text(), ".b") {
+			t.Errorf("unexpected listing:\n%s", res.Text())
+		}
+		client.Get("/deny/files/").Expect(404)
+	})
+}
+
+func TestStaticSPAFallbackAndPrecompressed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"index.html":   "<div id=app></div>",
+		"bundle.js":    "plain",
+		"bundle.js.gz": "gzipped",
+		"bundle.js.br": "brotli",
+		"style.css":    "body{}",
+	})
+	app := http.New()
+	app.Static("/", dir, &http.StaticOptions{SPA: true})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/settings/profile").Expect(200).ExpectBody("<div id=app></div>")
+		client.Get("/missing.png").Expect(404)
+
+		client.Get("/bundle.js").Header("Accept-Encoding", "gzip, br").
+			Expect(200).ExpectHeader("Content-Encoding", "br").ExpectHeader("Vary", "Accept-Encoding").ExpectBody("brotli")
+		client.Get("/bundle.js").Header("Accept-Encoding", "gzip").
+			Expect(200).ExpectHeader("Content-Encoding", "gzip").ExpectBody("gzipped")
+		client.Get("/bundle.js").Expect(200).ExpectHeader("Content-Encoding", "").ExpectBody("plain")
+		client.Get("/style.css").Header("Accept-Encoding", "gzip").ExpectHeader("Content-Encoding", "").ExpectBody("body{}")
+	})
+}
+
+func TestStaticCanDisableETag(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.txt": "a"})
+	app := http.New()
+	app.Static("/", dir, &http.StaticOptions{DisableETag: true})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/a.txt").Expect(200).ExpectHeader("ETag", "").ExpectHeader("Cache-Control", "public, max-age=0")
+	})
+}
+
+func TestStaticStaysInsideItsRoot(t *testing.T) {
+	outside := writeFiles(t, map[string]string{"secret.txt": "secret", "private/key.txt": "key"})
+	dir := writeFiles(t, map[string]string{"a.txt": "a", "shared/b.txt": "b"})
+	links := map[string]string{
+		"secret.txt": filepath.Join(outside, "secret.txt"),
+		"private":    filepath.Join(outside, "private"),
+		"alias.txt":  filepath.Join(dir, "a.txt"),
+		"docs":       filepath.Join(dir, "shared"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Skip("symlinks are not supported: ", err)
+		}
+	}
+
+	app := http.New()
+	app.Static("/", dir, &http.StaticOptions{Browse: true})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/secret.txt").Expect(404)
+		client.Get("/private/key.txt").Expect(404)
+		client.Get("/private/").Expect(404)
+
+		// symlinks that stay inside the root are followed
+		client.Get("/alias.txt").Expect(200).ExpectBody("a")
+		client.Get("/docs/b.txt").Expect(200).ExpectBody("b")
+	})
+}
+
+func TestStaticServesAnFS(t *testing.T) {
+	app := http.New()
+	app.Static("/", "public", &http.StaticOptions{FS: fstest.MapFS{
+		"public/index.html": {Data: []byte("embedded index")},
+		"public/a.txt":      {Data: []byte("embedded a")},
+		"secret.txt":        {Data: []byte("outside the root")},
+	}})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").Expect(200).ExpectBody("embedded index")
+		client.Get("/a.txt").Expect(200).ExpectBody("embedded a")
+		client.Get("/a.txt").Header("Range", "bytes=0-7").Expect(206).ExpectBody("embedded")
+		client.Get("/secret.txt").Expect(404)
+	})
+}
+
+func TestStaticReadsFromDiskInDevMode(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.txt": "from disk"})
+	embedded := fstest.MapFS{
+		strings.Trim(filepath.ToSlash(dir), "/") + "/a.txt": {Data: []byte("embedded")},
+	}
+	app := http.New()
+	app.Static("/", dir, &http.StaticOptions{FS: embedded})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/a.txt").Expect(200).ExpectBody("embedded")
+		app.SetServerOptions(&http.ServerOptions{Dev: true})
+		client.Get("/a.txt").Expect(200).ExpectBody("from disk")
+	})
+}
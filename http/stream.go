@@ -0,0 +1,132 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+)
+
+type JSONStreamOptions struct {
+	Array bool
+}
+
+// Stream calls step until it returns false or the client disconnects,
+// flushing whatever step wrote after every call.
+func (ctx *Context) Stream(step func(w io.Writer) bool) error {
+	w := ctx.Response.Writer
+	controller := http.NewResponseController(w)
+	w.Header().Del("Content-Length")
+
+	for {
+		if err := ctx.Context().Err(); err != nil {
+			return err
+		}
+		more := step(w)
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// Pipe copies r to the response, flushing after every chunk so slow sources reach the client as they arrive.
+func (ctx *Context) Pipe(r io.Reader) error {
+	w := ctx.Response.Writer
+	controller := http.NewResponseController(w)
+	w.Header().Del("Content-Length")
+
+	if closer, ok := r.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		if err := ctx.Context().Err(); err != nil {
+			return err
+		}
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+func (ctx *Context) JSONStream(seq iter.Seq[any], options *JSONStreamOptions) error {
+	return StreamJSON(ctx, seq, options)
+}
+
+// StreamJSON writes the values of seq as NDJSON, or as a single JSON array when
+// options.Array is set, encoding and flushing one value at a time.
+func StreamJSON[T any](ctx *Context, seq iter.Seq[T], options *JSONStreamOptions) error {
+	array := options != nil && options.Array
+
+	w := ctx.Response.Writer
+	controller := http.NewResponseController(w)
+	if array {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Del("Content-Length")
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+
+	flush := func() error {
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	if array {
+		buffered.WriteString("[")
+	}
+
+	var streamErr error
+	first := true
+	for value := range seq {
+		if streamErr = ctx.Context().Err(); streamErr != nil {
+			break
+		}
+		if array && !first {
+			buffered.WriteString(",")
+		}
+		first = false
+		// Encode appends a newline, which is the record separator for NDJSON
+		// and harmless whitespace inside an array
+		if streamErr = encoder.Encode(value); streamErr != nil {
+			break
+		}
+		if streamErr = flush(); streamErr != nil {
+			break
+		}
+	}
+
+	if streamErr != nil {
+		return streamErr
+	}
+	if array {
+		buffered.WriteString("]")
+	}
+	return flush()
+}
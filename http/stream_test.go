@@ -0,0 +1,156 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	nethttp "net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestStreamWritesEveryStep(t *testing.T) {
+	app := http.New()
+	app.Get("/count", func(ctx *http.Context) {
+		i := 0
+		ctx.Stream(func(w io.Writer) bool {
+			i++
+			fmt.Fprintf(w, "%d;", i)
+			return i < 3
+		})
+	})
+	app.Get("/pipe", func(ctx *http.Context) {
+		ctx.Pipe(io.NopCloser(strings.NewReader(strings.Repeat("x", 100_000))))
+	})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/count").Expect(200).ExpectBody("1;2;3;")
+		if res := client.Get("/pipe").Expect(200).Response(); len(res.Body) != 100_000 {
+			t.Errorf("piped %d bytes", len(res.Body))
+		}
+	})
+}
+
+func TestJSONStreamEncodesIncrementally(t *testing.T) {
+	values := func(yield func(any) bool) {
+		for _, v := range []any{map[string]int{"id": 1}, "two", 3} {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	app := http.New()
+	app.Get("/ndjson", func(ctx *http.Context) { ctx.JSONStream(values, nil) })
+	app.Get("/array", func(ctx *http.Context) { ctx.JSONStream(values, &http.JSONStreamOptions{Array: true}) })
+	app.Get("/empty", func(ctx *http.Context) {
+		http.StreamJSON(ctx, slices.Values([]string{}), &http.JSONStreamOptions{Array: true})
+	})
+	app.Get("/typed", func(ctx *http.Context) { http.StreamJSON(ctx, slices.Values([]int{4, 5}), nil) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/ndjson").Expect(200).
+			ExpectHeader("Content-Type", "application/x-ndjson").
+			ExpectBody("{\"id\":1}\n\"two\"\n3\n")
+		client.Get("/array").Expect(200).
+			ExpectHeader("Content-Type", "application/json").
+			ExpectJSON([]any{map[string]any{"id": 1}, "two", 3})
+		client.Get("/empty").ExpectBody("[]")
+		client.Get("/typed").ExpectBody("4\n5\n")
+	})
+}
+
+// the client sees each value as soon as it is yielded, and the stream stops
+// once the client goes away
+func TestStreamsFlushAndStopOnDisconnect(t *testing.T) {
+	release := make(chan struct{})
+	result := make(chan error, 1)
+	app := http.New()
+	app.Get("/feed", func(ctx *http.Context) {
+		var ticks iter.Seq[int] = func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if i == 1 {
+					<-release
+				}
+				if !yield(i) {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+		result <- http.StreamJSON(ctx, ticks, nil)
+	})
+
+	captureStdout(t, func() {
+		server := httptest.NewServer(app)
+		defer server.Close()
+
+		reqCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, _ := nethttp.NewRequestWithContext(reqCtx, nethttp.MethodGet, server.URL+"/feed", nil)
+		res, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		// the first value arrives while the iterator is still blocked
+		line, err := bufio.NewReader(res.Body).ReadString('\n')
+		if err != nil || line != "0\n" {
+			t.Fatalf("expected the first value before the stream ends, got %q, %v", line, err)
+		}
+
+		cancel()
+		close(release)
+		select {
+		case err := <-result:
+			if err == nil {
+				t.Errorf("expected the stream to stop with an error, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream kept running after the client disconnected")
+		}
+	})
+}
+
+// the Timeout middleware buffers the response and cannot flush, which must not
+// cut the stream short
+func TestStreamsThroughWritersWithoutFlush(t *testing.T) {
+	errs := make(chan error, 3)
+	app := http.New()
+	app.Get("/stream", func(ctx *http.Context) {
+		i := 0
+		errs <- ctx.Stream(func(w io.Writer) bool {
+			i++
+			fmt.Fprintf(w, "%d;", i)
+			return i < 3
+		})
+	}).Timeout(time.Second)
+	app.Get("/pipe", func(ctx *http.Context) {
+		errs <- ctx.Pipe(io.MultiReader(strings.NewReader("a"), strings.NewReader("b"), strings.NewReader("c")))
+	}).Timeout(time.Second)
+	app.Get("/json", func(ctx *http.Context) {
+		errs <- http.StreamJSON(ctx, slices.Values([]int{1, 2, 3}), nil)
+	}).Timeout(time.Second)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/stream").Expect(200).ExpectBody("1;2;3;")
+		client.Get("/pipe").Expect(200).ExpectBody("abc")
+		client.Get("/json").Expect(200).ExpectBody("1\n2\n3\n")
+	})
+	for range 3 {
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected stream error %v", err)
+		}
+	}
+}
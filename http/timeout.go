@@ -0,0 +1,167 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type TimeoutOptions struct {
+	StatusCode  int
+	Body        string
+	ContentType string
+}
+
+// Timeout cancels the request context after d and answers with a 503 (or the
+// configured status) when the rest of the chain has not finished by then.
+// The chain's output is buffered so a late handler can never write over the timeout
+// response. The timeout response is flushed right away, but the middleware only
+// returns once the chain has, so handlers should stop when ctx.Context() is done.
+func Timeout(d time.Duration, options *TimeoutOptions) Middleware {
+	status := http.StatusServiceUnavailable
+	body := "Service Unavailable"
+	contentType := "text/plain; charset=utf-8"
+
+	if options != nil {
+		if options.StatusCode != 0 {
+			status = options.StatusCode
+		}
+		if options.Body != "" {
+			body = options.Body
+		}
+		if options.ContentType != "" {
+			contentType = options.ContentType
+		}
+	}
+
+	return func(ctx *Context, next func()) {
+		if d <= 0 {
+			next()
+			return
+		}
+
+		c, cancel := context.WithTimeout(ctx.Context(), d)
+		defer cancel()
+		ctx.SetContext(c)
+
+		original := ctx.Response.Writer
+		tw := &timeoutWriter{header: original.Header().Clone()}
+		ctx.Response.Writer = tw
+
+		done := make(chan struct{})
+		panicked := make(chan *PanicError, 1)
+
+		go func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicked <- wrapPanic(recovered)
+				}
+			}()
+			next()
+			close(done)
+		}()
+
+		select {
+		case err := <-panicked:
+			ctx.Response.Writer = original
+			panic(err)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			ctx.Response.Writer = original
+			tw.flushTo(original)
+		case <-c.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+
+			// the client went away when the context is canceled; there is nobody left to answer
+			if c.Err() != context.Canceled {
+				original.Header().Set("Content-Type", contentType)
+				original.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				original.WriteHeader(status)
+				original.Write([]byte(body))
+				if f, ok := original.(http.Flusher); ok {
+					f.Flush()
+				}
+			}
+
+			// the rest of the chain shares ctx, so wait for it before the middlewares
+			// around this one read the response; the client already has its answer
+			var err *PanicError
+			select {
+			case err = <-panicked:
+			case <-done:
+			}
+
+			ctx.Response.Writer = original
+			if c.Err() != context.Canceled {
+				ctx.Response.StatusCode = status
+			}
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	written  bool
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.written {
+		return
+	}
+	tw.status = code
+	tw.written = true
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.written {
+		tw.status = http.StatusOK
+		tw.written = true
+	}
+	return tw.buf.Write(b)
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.written
+}
+
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	dst := w.Header()
+	for key := range dst {
+		delete(dst, key)
+	}
+	for key, values := range tw.header {
+		dst[key] = values
+	}
+	if tw.written {
+		w.WriteHeader(tw.status)
+	}
+	if tw.buf.Len() > 0 {
+		w.Write(tw.buf.Bytes())
+	}
+}
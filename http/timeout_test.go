@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestTimeoutLateHandlerDoesNotRace(t *testing.T) {
+	app := http.New()
+	app.Use(http.Timeout(10*time.Millisecond, nil))
+	app.Get("/slow", func(ctx *http.Context) {
+		time.Sleep(50 * time.Millisecond)
+		ctx.Status(200)
+		ctx.Response.Send("late")
+	})
+
+	client := expresstest.New(t, app)
+	output := captureStdout(t, func() {
+		client.Get("/slow").Expect(503).ExpectBody("Service Unavailable")
+	})
+	if !strings.Contains(output, "GET /slow - 503") {
+		t.Errorf("expected the timeout to be logged as 503:\n%s", output)
+	}
+}
+
+func TestTimeoutPassesFastResponsesThrough(t *testing.T) {
+	app := http.New()
+	app.Use(http.Timeout(time.Second, nil))
+	app.Get("/fast", func(ctx *http.Context) {
+		ctx.Response.Writer.Header().Set("X-Fast", "1")
+		ctx.Response.Status(201).Send("done")
+	})
+
+	expresstest.New(t, app).Get("/fast").Expect(201).ExpectBody("done").ExpectHeader("X-Fast", "1")
+}
+
+func TestTimeoutCancelsTheRequestContext(t *testing.T) {
+	app := http.New()
+	stopped := make(chan struct{})
+	app.Get("/wait", func(ctx *http.Context) {
+		<-ctx.Context().Done()
+		close(stopped)
+	}).Timeout(10 * time.Millisecond)
+
+	expresstest.New(t, app).Get("/wait").Expect(503)
+	select {
+	case <-stopped:
+	default:
+		t.Error("handler still running after the middleware returned")
+	}
+}
+
+func TestTimeoutOptions(t *testing.T) {
+	app := http.New()
+	app.Use(http.Timeout(5*time.Millisecond, &http.TimeoutOptions{
+		StatusCode:  504,
+		Body:        `{"error":"timeout"}`,
+		ContentType: "application/json",
+	}))
+	app.Get("/slow", func(ctx *http.Context) { <-ctx.Context().Done() })
+
+	expresstest.New(t, app).Get("/slow").Expect(504).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJSON(map[string]any{"error": "timeout"})
+}
+
+func TestTimeoutReportsPanicsAfterTheDeadline(t *testing.T) {
+	app := http.New()
+	reported := make(chan any, 1)
+	app.SetRecover(&http.RecoverOptions{Reporter: func(ctx *http.Context, err *http.PanicError) { reported <- err.Value }})
+	app.Get("/boom", func(ctx *http.Context) {
+		<-ctx.Context().Done()
+		panic("late boom")
+	}).Timeout(5 * time.Millisecond)
+
+	expresstest.New(t, app).Get("/boom").Expect(503)
+	if value := <-reported; value != "late boom" {
+		t.Errorf("reported %v", value)
+	}
+}
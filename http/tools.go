@@ -1,7 +1,9 @@
 package http
 
 import (
+	"fmt"
 	"net/url"
+	"os"
 )
 
 type LoggerType interface {
@@ -38,7 +40,7 @@ func (l logger) Log(message string) {
 	println(message)
 	if len(l.fields) > 0 {
 		for k, v := range l.fields {
-			println(k+":", v)
+			fmt.Fprintln(os.Stderr, k+":", v)
 		}
 	}
 }
@@ -51,7 +53,7 @@ func (l logger) Debug(message string) {
 	println("DEBUG:", message)
 	if len(l.fields) > 0 {
 		for k, v := range l.fields {
-			println(k+":", v)
+			fmt.Fprintln(os.Stderr, k+":", v)
 		}
 	}
 }
@@ -64,7 +66,7 @@ func (l logger) Error(message string) {
 	println("ERROR:", message)
 	if len(l.fields) > 0 {
 		for k, v := range l.fields {
-			println(k+":", v)
+			fmt.Fprintln(os.Stderr, k+":", v)
 		}
 	}
 }
@@ -77,7 +79,7 @@ func (l logger) Info(message string) {
 	println("INFO:", message)
 	if len(l.fields) > 0 {
 		for k, v := range l.fields {
-			println(k+":", v)
+			fmt.Fprintln(os.Stderr, k+":", v)
 		}
 	}
 }
@@ -90,7 +92,7 @@ func (l logger) Warn(message string) {
 	println("WARN:", message)
 	if len(l.fields) > 0 {
 		for k, v := range l.fields {
-			println(k+":", v)
+			fmt.Fprintln(os.Stderr, k+":", v)
 		}
 	}
 }
@@ -103,7 +105,7 @@ func (l logger) Fatal(message string) {
 	println("FATAL:", message)
 	if len(l.fields) > 0 {
 		for k, v := range l.fields {
-			println(k+":", v)
+			fmt.Fprintln(os.Stderr, k+":", v)
 		}
 
 	}
@@ -117,7 +119,7 @@ func (l logger) Trace(message string) {
 	println("TRACE:", message)
 	if len(l.fields) > 0 {
 		for k, v := range l.fields {
-			println(k+":", v)
+			fmt.Fprintln(os.Stderr, k+":", v)
 		}
 	}
 }
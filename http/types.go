@@ -1,6 +1,9 @@
 package http
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 type Request struct {
 	r                *http.Request
@@ -20,6 +23,11 @@ type Response struct {
 type Context struct {
 	Request  *Request
 	Response *Response
+	Locals   map[string]any
+	server   *Server
+	root     *Context     // first context of the request, set for mounted sub-applications
+	chain    []Middleware // the running middleware and the ones after it
+	handler  Handler      // the handler at the end of chain
 }
 
 type Handler func(*Context)
@@ -29,13 +37,47 @@ type ErrorHandlerType func(*Context, error)
 type Middleware func(ctx *Context, next func())
 
 type Server struct {
-	Port         int
-	Address      string
-	Request      *Request
-	Response     *Response
-	ErrorHandler ErrorHandlerType
-	Routes       map[string][]Route
-	Middlewares  []Middleware
+	Port              int
+	Address           string
+	Request           *Request
+	Response          *Response
+	ErrorHandler      ErrorHandlerType
+	Routes            map[string][]Route
+	Middlewares       []Middleware
+	Locals            map[string]any
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	Dev               bool
+	LogRoutes         bool
+	StrictRoutes      bool
+	WebSocket         *WSOptions
+	NotFoundHandler   Handler
+	views             *viewSet
+	viewsSet          bool
+	errorHandlerSet   bool
+	parent            *Server
+	apps              []*appMount
+	hosts             []*VirtualHost
+	versions          []*APIVersions
+	conflicts         []RouteConflict
+	recoverOptions    *RecoverOptions
+	builtIns          int // leading Middlewares installed by CreateServer
+}
+
+type ServerOptions struct {
+	Address           string
+	Dev               bool
+	LogRoutes         bool // print the route table when Listen starts
+	StrictRoutes      bool // panic on route conflicts instead of logging them
+	WebSocket         *WSOptions
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
 }
 
 type HTTPMethod func(path string, handler Handler) *RouteChain
@@ -48,6 +90,18 @@ type Route struct {
 	SearchParams map[string]string
 	Middlewares  []Middleware
 	Name         string
+	Meta         map[string]any
+	Tags         []string
+	Summary      string
+	Deprecated   bool
+	Timeout      time.Duration
+	BodyLimit    int64
+	mountPath    string
+	source       string
+	builtIns     int
+	deprecation  DeprecationOptions
+
+	routeMiddlewares []Middleware
 }
 
 type RouteChain struct {
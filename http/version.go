@@ -0,0 +1,260 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type VersionOptions struct {
+	Prefix  string // path the versions are served under, "/" by default
+	Default string // version used when the request asks for none, the latest by default
+	Vendor  string // only accept application/vnd.<Vendor>.vN media types; any vendor when empty
+}
+
+// APIVersions serves several versions of an API side by side. A request asks
+// for a version with a /vN path segment, an Accept-Version header or a media
+// type (application/vnd.app.v2+json or application/json; version=2), and is
+// served by the newest version up to that one that has a matching route, so a
+// version only needs the routes that changed.
+type APIVersions struct {
+	prefix      string
+	pattern     *routePattern
+	defaultVer  []int
+	vendor      string
+	versions    []*APIVersion
+	parent      *Server
+	middlewares []Middleware
+	builtIns    int
+}
+
+type APIVersion struct {
+	name        string
+	number      []int
+	server      *Server
+	deprecated  bool
+	deprecation DeprecationOptions
+}
+
+var (
+	versionSegmentRegex = regexp.MustCompile(`^[vV](\d+(?:\.\d+)*)$`)
+	vendorMediaRegex    = regexp.MustCompile(`^application/vnd\.([^+]+)\.v(\d+(?:\.\d+)*)(?:\+|$)`)
+)
+
+func (s *Server) Versioned(options *VersionOptions) *APIVersions {
+	opts := VersionOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	prefix := normalizeMountPrefix(opts.Prefix)
+	versions := &APIVersions{
+		prefix:      prefix,
+		pattern:     compileRoute(prefix, true),
+		vendor:      opts.Vendor,
+		parent:      s,
+		middlewares: append([]Middleware{}, s.Middlewares...),
+		builtIns:    s.builtIns,
+	}
+	if opts.Default != "" {
+		versions.defaultVer = parseVersion(opts.Default)
+	}
+	s.versions = append(s.versions, versions)
+	return versions
+}
+
+// Version serves router as version ("1", "v2", "2.1"). Calling it again with
+// the same version adds another router.
+func (v *APIVersions) Version(version string, router *Router) *APIVersion {
+	if router == nil {
+		panic("Router cannot be nil")
+	}
+
+	number := parseVersion(version)
+	apiVersion := v.find(number)
+	if apiVersion == nil {
+		apiVersion = &APIVersion{
+			name:   formatVersion(number),
+			number: number,
+			server: &Server{
+				Routes: make(map[string][]Route),
+				Locals: make(map[string]any),
+				parent: v.parent,
+			},
+		}
+		v.versions = append(v.versions, apiVersion)
+		sort.Slice(v.versions, func(i, j int) bool {
+			return compareVersions(v.versions[i].number, v.versions[j].number) > 0
+		})
+	}
+
+	router.attach(routerMount{
+		server: apiVersion.server,
+		prefix: "/",
+	})
+	return apiVersion
+}
+
+// Deprecate marks the version as deprecated: its responses carry the
+// Deprecation, Sunset and Link headers of options. nil options date the
+// deprecation now.
+func (v *APIVersion) Deprecate(options *DeprecationOptions) *APIVersion {
+	v.deprecated = true
+	v.deprecation = newDeprecation(options)
+	return v
+}
+
+func (v *APIVersions) find(number []int) *APIVersion {
+	for _, version := range v.versions {
+		if compareVersions(version.number, number) == 0 {
+			return version
+		}
+	}
+	return nil
+}
+
+func (v *APIVersions) serve(w http.ResponseWriter, r *http.Request, parent *Context) bool {
+	params, matched, ok := v.pattern.match(r.URL.Path)
+	if !ok || len(v.versions) == 0 {
+		return false
+	}
+	matched = strings.TrimSuffix(matched, "/")
+
+	requested, inPath := v.requestedVersion(r, matched)
+	if inPath != "" {
+		matched += "/" + inPath
+	}
+	stripped := stripPrefix(r, matched)
+
+	// versions are sorted newest first
+	for _, version := range v.versions {
+		if requested != nil && compareVersions(version.number, requested) > 0 {
+			continue
+		}
+		if !version.server.hasRoute(stripped.Method, stripped.URL.Path) {
+			continue
+		}
+
+		ctx := v.parent.newContext(w, r, params, parent)
+		ctx.setMount(v.prefix, ctx.BaseURL()+matched)
+		ctx.Request.AdditionalFields["apiVersion"] = version.name
+
+		server := version.server
+		chainMiddlewares(ownMiddlewares(v.middlewares, v.builtIns, parent), func(ctx *Context) {
+			header := ctx.Response.Writer.Header()
+			header.Set("API-Version", version.name)
+			if version.deprecated {
+				version.deprecation.setHeaders(header)
+			}
+			server.serve(ctx.Response.Writer, stripped, ctx)
+		})(ctx)
+		return true
+	}
+	return false
+}
+
+// requestedVersion returns the version the request asks for (nil for the
+// default) and the /vN path segment it was read from, if any.
+func (v *APIVersions) requestedVersion(r *http.Request, matched string) ([]int, string) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, matched), "/")
+	segment, _, _ := strings.Cut(rest, "/")
+	if match := versionSegmentRegex.FindStringSubmatch(segment); match != nil {
+		return parseVersion(match[1]), segment
+	}
+
+	if header := strings.TrimSpace(r.Header.Get("Accept-Version")); header != "" {
+		if number, ok := tryParseVersion(header); ok {
+			return number, ""
+		}
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, mediaParams, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if match := vendorMediaRegex.FindStringSubmatch(mediaType); match != nil {
+			if v.vendor == "" || strings.EqualFold(match[1], v.vendor) {
+				return parseVersion(match[2]), ""
+			}
+			continue
+		}
+		if version, ok := mediaParams["version"]; ok {
+			if number, ok := tryParseVersion(version); ok {
+				return number, ""
+			}
+		}
+	}
+
+	return v.defaultVer, ""
+}
+
+func (s *Server) hasRoute(method string, path string) bool {
+	for _, route := range s.Routes[method] {
+		if matchRoute(route.Path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIVersion returns the version that served the request ("" outside versioned routes).
+func (ctx *Context) APIVersion() string {
+	version, _ := ctx.Request.AdditionalFields["apiVersion"].(string)
+	return version
+}
+
+func parseVersion(version string) []int {
+	number, ok := tryParseVersion(version)
+	if !ok {
+		panic("Invalid API version: " + version)
+	}
+	return number
+}
+
+func tryParseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(version), "v"), "V")
+	if version == "" {
+		return nil, false
+	}
+	number := []int{}
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		number = append(number, n)
+	}
+	return number, true
+}
+
+func formatVersion(number []int) string {
+	parts := make([]string, len(number))
+	for i, n := range number {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// compareVersions compares dotted versions, treating missing parts as 0 (2 == 2.0).
+func compareVersions(a []int, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package http_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func versionedApp(options *http.VersionOptions) *http.Application {
+	reply := func(body string) http.Handler {
+		return func(ctx *http.Context) {
+			ctx.Response.Writer.Header().Set("X-Context-Version", ctx.APIVersion())
+			ctx.Send(body + " " + ctx.BaseURL())
+		}
+	}
+
+	v1 := http.NewRouter()
+	v1.Get("/users", reply("users v1"))
+	v1.Get("/orders", reply("orders v1"))
+	v1.Delete("/users/:id", reply("delete v1"))
+
+	v2 := http.NewRouter()
+	v2.Get("/users", reply("users v2"))
+
+	v3 := http.NewRouter()
+	v3.Get("/reports", reply("reports v3"))
+
+	app := http.New()
+	api := app.Versioned(options)
+	api.Version("1", v1).Deprecate(&http.DeprecationOptions{
+		Date:   time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Link:   "https://example.com/api/v1-retirement",
+	})
+	api.Version("v2", v2)
+	api.Version("3.0", v3)
+	return app
+}
+
+func TestVersionSelection(t *testing.T) {
+	app := versionedApp(&http.VersionOptions{Prefix: "/api", Vendor: "shop"})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/api/v1/users").Expect(200).ExpectHeader("API-Version", "1").ExpectBody("users v1 /api/v1")
+		client.Get("/api/v2/users").Expect(200).ExpectBody("users v2 /api/v2")
+
+		// without a version the newest matching version answers
+		client.Get("/api/users").Expect(200).ExpectHeader("API-Version", "2").ExpectBody("users v2 /api")
+		client.Get("/api/reports").Expect(200).
+			ExpectHeader("API-Version", "3.0").
+			ExpectHeader("X-Context-Version", "3.0").
+			ExpectBody("reports v3 /api")
+
+		// a version falls back to the newest older one that has the route
+		client.Get("/api/v3/users").Expect(200).ExpectBody("users v2 /api/v3")
+		client.Get("/api/v2/orders").Expect(200).ExpectHeader("API-Version", "1").ExpectBody("orders v1 /api/v2")
+		client.Delete("/api/v2/users/5").Expect(200).ExpectBody("delete v1 /api/v2")
+		client.Get("/api/v2/reports").Expect(404)
+
+		client.Get("/api/users").Header("Accept-Version", "1").ExpectBody("users v1 /api")
+		client.Get("/api/users").Header("Accept", "application/vnd.shop.v1+json").ExpectBody("users v1 /api")
+		client.Get("/api/users").Header("Accept", "application/vnd.other.v1+json").ExpectBody("users v2 /api")
+		client.Get("/api/users").Header("Accept", "text/html, application/json; version=1").ExpectBody("users v1 /api")
+
+		client.Get("/users").Expect(404)
+	})
+}
+
+func TestVersionDefaultAndDeprecation(t *testing.T) {
+	app := versionedApp(&http.VersionOptions{Default: "1"})
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/users").Expect(200).
+			ExpectHeader("Deprecation", "@1861920000").
+			ExpectHeader("Sunset", "Tue, 01 Jan 2030 00:00:00 GMT").
+			ExpectHeader("Link", `<https://example.com/api/v1-retirement>; rel="deprecation"; type="text/html"`).
+			ExpectBody("users v1 ")
+		client.Get("/v2/users").Expect(200).ExpectHeader("Deprecation", "").ExpectHeader("Sunset", "").ExpectBody("users v2 /v2")
+	})
+}
+
+func TestVersionsAreListedInRoutes(t *testing.T) {
+	app := versionedApp(&http.VersionOptions{Prefix: "/api"})
+
+	got := map[string]http.RouteEntry{}
+	for _, entry := range app.Routes() {
+		got[entry.Method+" "+entry.Pattern] = entry
+	}
+	if entry := got["GET /api/v1/users"]; entry.Version != "1" || !entry.Deprecated {
+		t.Errorf("unexpected v1 entry %+v", entry)
+	}
+	if entry := got["GET /api/v3.0/reports"]; entry.Version != "3.0" || entry.Deprecated {
+		t.Errorf("unexpected v3 entry %+v", entry)
+	}
+	if len(got) != 5 {
+		t.Errorf("expected 5 routes, got %v", got)
+	}
+}
+
+func TestInvalidVersionPanics(t *testing.T) {
+	api := http.New().Versioned(nil)
+	defer func() {
+		if recovered := recover(); recovered != "Invalid API version: beta" {
+			t.Errorf("unexpected panic %v", recovered)
+		}
+	}()
+	api.Version("beta", http.NewRouter())
+}
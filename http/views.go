@@ -0,0 +1,234 @@
+package http
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ViewEngine renders named templates. Load is called once up front (and on
+// every render in dev mode) so engines can pre-parse their templates.
+type ViewEngine interface {
+	Load() error
+	Render(w io.Writer, name string, data any, layout string) error
+}
+
+type ViewOptions struct {
+	FS        fs.FS
+	Dir       string
+	Extension string
+	Layout    string
+	Layouts   string
+	Partials  string
+	Funcs     template.FuncMap
+	Engine    ViewEngine
+}
+
+type viewSet struct {
+	engine ViewEngine
+	disk   ViewEngine
+	layout string
+	mu     sync.Mutex
+	loaded bool
+}
+
+func newViewSet(options *ViewOptions) *viewSet {
+	opts := ViewOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Dir == "" {
+		opts.Dir = "templates"
+	}
+
+	v := &viewSet{layout: opts.Layout}
+
+	if opts.Engine != nil {
+		v.engine = opts.Engine
+		return v
+	}
+
+	absDir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		panic("Failed to resolve views directory: " + err.Error())
+	}
+	v.disk = NewHTMLEngine(os.DirFS(absDir), &opts)
+	v.engine = v.disk
+
+	if opts.FS != nil {
+		fsys := opts.FS
+		if opts.Dir != "." {
+			sub, err := fs.Sub(opts.FS, strings.Trim(path.Clean(filepath.ToSlash(opts.Dir)), "/"))
+			if err != nil {
+				panic("Failed to resolve views directory: " + err.Error())
+			}
+			fsys = sub
+		}
+		v.engine = NewHTMLEngine(fsys, &opts)
+	}
+
+	return v
+}
+
+// render loads the engine on first use. In dev mode templates are reloaded
+// from disk on every render so edits show up without a restart.
+func (v *viewSet) render(w io.Writer, name string, data any, layout string, dev bool) error {
+	engine := v.engine
+	if dev {
+		if v.disk != nil {
+			engine = v.disk
+		}
+		if err := engine.Load(); err != nil {
+			return err
+		}
+		return engine.Render(w, name, data, layout)
+	}
+
+	v.mu.Lock()
+	if !v.loaded {
+		if err := engine.Load(); err != nil {
+			v.mu.Unlock()
+			return err
+		}
+		v.loaded = true
+	}
+	v.mu.Unlock()
+
+	return engine.Render(w, name, data, layout)
+}
+
+func (s *Server) SetViews(options *ViewOptions) {
+	views := newViewSet(options)
+	if err := views.engine.Load(); err != nil {
+		panic("Failed to load views: " + err.Error())
+	}
+	views.loaded = true
+	s.views = views
+	s.viewsSet = true
+}
+
+// HTMLEngine is the default ViewEngine built on html/template. Files under the
+// layouts and partials directories are shared by every page; each page is parsed
+// into its own copy of that set so pages can define the same blocks independently.
+type HTMLEngine struct {
+	fsys      fs.FS
+	extension string
+	layouts   string
+	partials  string
+	funcs     template.FuncMap
+
+	mu        sync.RWMutex
+	templates map[string]*template.Template
+}
+
+func NewHTMLEngine(fsys fs.FS, options *ViewOptions) *HTMLEngine {
+	e := &HTMLEngine{
+		fsys:      fsys,
+		extension: ".html",
+		layouts:   "layouts",
+		partials:  "partials",
+		funcs:     template.FuncMap{},
+		templates: make(map[string]*template.Template),
+	}
+	if options != nil {
+		if options.Extension != "" {
+			e.extension = options.Extension
+		}
+		if options.Layouts != "" {
+			e.layouts = options.Layouts
+		}
+		if options.Partials != "" {
+			e.partials = options.Partials
+		}
+		if options.Funcs != nil {
+			e.funcs = options.Funcs
+		}
+	}
+	return e
+}
+
+func (e *HTMLEngine) Load() error {
+	shared := map[string]string{}
+	pages := map[string]string{}
+
+	err := fs.WalkDir(e.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(name, e.extension) {
+			return nil
+		}
+		content, err := fs.ReadFile(e.fsys, name)
+		if err != nil {
+			return err
+		}
+		if isInDir(name, e.layouts) || isInDir(name, e.partials) {
+			shared[name] = string(content)
+		} else {
+			pages[name] = string(content)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	base := template.New("").Funcs(e.funcs)
+	for name, content := range shared {
+		if _, err := base.New(name).Parse(content); err != nil {
+			return err
+		}
+	}
+
+	templates := make(map[string]*template.Template, len(pages))
+	for name, content := range pages {
+		t, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		if _, err := t.New(name).Parse(content); err != nil {
+			return err
+		}
+		templates[name] = t
+	}
+
+	e.mu.Lock()
+	e.templates = templates
+	e.mu.Unlock()
+
+	return nil
+}
+
+func (e *HTMLEngine) Render(w io.Writer, name string, data any, layout string) error {
+	name = e.normalize(name)
+
+	e.mu.RLock()
+	t, ok := e.templates[name]
+	e.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("template %q not found", name)
+	}
+
+	if layout == "" {
+		return t.ExecuteTemplate(w, name, data)
+	}
+	return t.ExecuteTemplate(w, e.normalize(layout), data)
+}
+
+func (e *HTMLEngine) normalize(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if path.Ext(name) == "" {
+		name += e.extension
+	}
+	return name
+}
+
+func isInDir(name string, dir string) bool {
+	return dir != "" && strings.HasPrefix(name, strings.Trim(dir, "/")+"/")
+}
@@ -0,0 +1,156 @@
+package http_test
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+func TestViewsFromAnFS(t *testing.T) {
+	app := http.New()
+	app.SetViews(&http.ViewOptions{
+		FS:  fstest.MapFS{"views/home.html": {Data: []byte("<p>{{.name}}</p>")}},
+		Dir: "views",
+	})
+	app.Get("/", func(ctx *http.Context) { ctx.Render("home", map[string]any{"name": "<embedded>"}) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").Expect(200).ExpectHeader("Content-Type", "text/html; charset=utf-8").ExpectBody("<p>&lt;embedded&gt;</p>")
+	})
+}
+
+func TestViewsReloadFromDiskInDevMode(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"home.html": "disk v1"})
+	app := http.New()
+	app.SetViews(&http.ViewOptions{
+		FS:  fstest.MapFS{strings.Trim(filepath.ToSlash(dir), "/") + "/home.html": {Data: []byte("embedded")}},
+		Dir: dir,
+	})
+	app.Get("/", func(ctx *http.Context) { ctx.Render("home", nil) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").ExpectBody("embedded")
+
+		app.SetServerOptions(&http.ServerOptions{Dev: true})
+		client.Get("/").ExpectBody("disk v1")
+		if err := os.WriteFile(filepath.Join(dir, "home.html"), []byte("disk v2"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		client.Get("/").ExpectBody("disk v2")
+	})
+}
+
+var templates = fstest.MapFS{
+	"layouts/main.html":  {Data: []byte(`<main>{{template "partials/nav.html" .}}{{block "content" .}}default{{end}}</main>`)},
+	"layouts/plain.html": {Data: []byte(`[{{block "content" .}}{{end}}]`)},
+	"partials/nav.html":  {Data: []byte(`<nav>{{.title}}</nav>`)},
+	"home.html":          {Data: []byte(`{{define "content"}}<h1>{{shout .title}}</h1>{{end}}`)},
+	"about.html":         {Data: []byte(`{{define "content"}}about{{end}}`)},
+	"raw.tmpl":           {Data: []byte(`not a view`)},
+}
+
+func TestViewsLayoutsPartialsAndFuncs(t *testing.T) {
+	app := http.New()
+	app.SetViews(&http.ViewOptions{
+		FS:     templates,
+		Dir:    ".",
+		Layout: "layouts/main",
+		Funcs:  template.FuncMap{"shout": strings.ToUpper},
+	})
+	app.Get("/", func(ctx *http.Context) { ctx.Render("home", map[string]any{"title": "hi"}) })
+	app.Get("/about", func(ctx *http.Context) { ctx.RenderWithLayout("about.html", "layouts/plain", nil) })
+	app.Get("/bare", func(ctx *http.Context) { ctx.RenderWithLayout("home", "", map[string]any{"title": "bare"}) })
+	app.Get("/missing", func(ctx *http.Context) { ctx.Render("raw", nil) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").Expect(200).ExpectHeader("Cache-Control", "no-cache, no-store, must-revalidate").
+			ExpectBody("<main><nav>hi</nav><h1>HI</h1></main>")
+		// each page is parsed on its own, so "about" does not leak into "home"
+		client.Get("/about").Expect(200).ExpectBody("[about]")
+		client.Get("/bare").Expect(200).ExpectBody("")
+		client.Get("/missing").Expect(500).ExpectBody(`Error rendering template: template "raw.html" not found`)
+	})
+}
+
+func TestViewsUseACustomEngine(t *testing.T) {
+	engine := &recordingEngine{}
+	app := http.New()
+	app.SetViews(&http.ViewOptions{Engine: engine, Layout: "base"})
+	app.Get("/", func(ctx *http.Context) { ctx.Render("page", map[string]any{"n": 1}) })
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").Expect(200).ExpectBody("page in base")
+	})
+	if engine.loads != 1 {
+		t.Errorf("expected the engine to be loaded once, got %d", engine.loads)
+	}
+}
+
+type recordingEngine struct {
+	loads int
+}
+
+func (e *recordingEngine) Load() error {
+	e.loads++
+	return nil
+}
+
+func (e *recordingEngine) Render(w io.Writer, name string, data any, layout string) error {
+	_, err := io.WriteString(w, name+" in "+layout)
+	return err
+}
+
+func TestSetViewsPanicsOnInvalidTemplates(t *testing.T) {
+	defer func() {
+		if recovered := recover(); recovered == nil || !strings.HasPrefix(recovered.(string), "Failed to load views") {
+			t.Errorf("expected SetViews to panic, got %v", recovered)
+		}
+	}()
+	http.New().SetViews(&http.ViewOptions{FS: fstest.MapFS{"broken.html": {Data: []byte("{{if}}")}}, Dir: "."})
+}
+
+func TestViewDataMergesLocals(t *testing.T) {
+	app := http.New()
+	app.SetViews(&http.ViewOptions{
+		FS: fstest.MapFS{
+			"page.html":   {Data: []byte(`{{.app}}|{{.user}}|{{.title}}`)},
+			"struct.html": {Data: []byte(`{{.Title}}`)},
+		},
+		Dir: ".",
+	})
+	app.Locals["app"] = "shop"
+	app.Locals["title"] = "app title"
+	app.Use(func(ctx *http.Context, next func()) {
+		ctx.Locals["user"] = "ada"
+		ctx.Locals["title"] = "request title"
+		next()
+	})
+	app.Get("/", func(ctx *http.Context) { ctx.Render("page", nil) })
+	app.Get("/override", func(ctx *http.Context) { ctx.Render("page", map[string]any{"title": "handler title"}) })
+	app.Get("/struct", func(ctx *http.Context) { ctx.Render("struct", struct{ Title string }{"from struct"}) })
+
+	admin := http.New()
+	admin.Locals["app"] = "admin"
+	admin.Get("/", func(ctx *http.Context) { ctx.Render("page", nil) })
+	app.UseApp("/admin", admin)
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/").ExpectBody("shop|ada|request title")
+		client.Get("/override").ExpectBody("shop|ada|handler title")
+		client.Get("/struct").ExpectBody("from struct")
+		// the mounted app uses the parent's views and its own locals first
+		client.Get("/admin").ExpectBody("admin|ada|request title")
+	})
+}
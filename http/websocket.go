@@ -0,0 +1,781 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	WSContinuationMessage = 0
+	WSTextMessage         = 1
+	WSBinaryMessage       = 2
+	WSCloseMessage        = 8
+	WSPingMessage         = 9
+	WSPongMessage         = 10
+)
+
+const (
+	WSCloseNormal          = 1000
+	WSCloseGoingAway       = 1001
+	WSCloseProtocolError   = 1002
+	WSCloseUnsupportedData = 1003
+	WSCloseNoStatus        = 1005
+	WSCloseAbnormal        = 1006
+	WSCloseInvalidPayload  = 1007
+	WSClosePolicyViolation = 1008
+	WSCloseMessageTooBig   = 1009
+	WSCloseInternalError   = 1011
+)
+
+const (
+	wsAcceptGUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsCloseGracePeriod   = 5 * time.Second
+	wsCompressMinLength  = 64
+	wsDefaultReadLimit   = 32 << 20
+	wsMaxControlPayload  = 125
+	wsDeflateTail        = "\x00\x00\xff\xff"
+	wsDeflateFinalMarker = "\x00\x00\xff\xff\x01\x00\x00\xff\xff"
+)
+
+type WSHandler func(conn *WSConn)
+
+type WSOptions struct {
+	Subprotocols      []string
+	CheckOrigin       func(ctx *Context) bool
+	EnableCompression bool
+	ReadLimit         int64
+	PingInterval      time.Duration
+	WriteTimeout      time.Duration
+	FragmentSize      int
+}
+
+type WSCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WSCloseError) Error() string {
+	return "websocket: close " + strconv.Itoa(e.Code) + " " + e.Reason
+}
+
+var ErrWSClosed = errors.New("websocket: connection closed")
+
+type WSConn struct {
+	Ctx         *Context
+	Subprotocol string
+
+	conn         net.Conn
+	reader       *bufio.Reader
+	writer       *bufio.Writer
+	writeMu      sync.Mutex
+	closeSent    bool
+	closeOnce    sync.Once
+	done         chan struct{}
+	compress     bool
+	readLimit    int64
+	fragmentSize int
+	writeTimeout time.Duration
+	pingInterval time.Duration
+	pongHandler  func(data string)
+}
+
+func (s *Server) WS(path string, handler WSHandler) *RouteChain {
+	s.AddRoute(path, wsRouteHandler(handler), []string{"GET"})
+	return &RouteChain{
+		server: s,
+		path:   path,
+		method: []string{"GET"},
+	}
+}
+
+func (r *Router) WS(path string, handler WSHandler) *RouteChain {
+	r.AddRoute(path, wsRouteHandler(handler), []string{"GET"})
+	return &RouteChain{
+		router: r,
+		path:   path,
+		method: []string{"GET"},
+	}
+}
+
+// wsRouteHandler upgrades after the route's middlewares have run, so auth,
+// logging and CORS checks apply to the handshake like to any other request.
+func wsRouteHandler(handler WSHandler) Handler {
+	if handler == nil {
+		panic("Handler cannot be empty")
+	}
+	return func(ctx *Context) {
+		var options *WSOptions
+		if ctx.server != nil {
+			options = ctx.server.WebSocket
+		}
+		conn, err := ctx.Upgrade(options)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}
+}
+
+func (ctx *Context) Upgrade(options *WSOptions) (*WSConn, error) {
+	opts := WSOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.ReadLimit <= 0 {
+		opts.ReadLimit = wsDefaultReadLimit
+	}
+	if opts.CheckOrigin == nil {
+		opts.CheckOrigin = isSameOrigin
+	}
+
+	r := ctx.Request.r
+	w := ctx.Response.Writer
+
+	fail := func(status int, message string) (*WSConn, error) {
+		if status == http.StatusUpgradeRequired {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		ctx.Response.StatusCode = status
+		w.WriteHeader(status)
+		w.Write([]byte(message))
+		return nil, errors.New("websocket: " + message)
+	}
+
+	if r.Method != http.MethodGet {
+		return fail(http.StatusMethodNotAllowed, "upgrade requires a GET request")
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, "missing websocket upgrade headers")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return fail(http.StatusUpgradeRequired, "unsupported websocket version")
+	}
+	key := strings.TrimSpace(r.Header.Get("Sec-WebSocket-Key"))
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return fail(http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+	if !opts.CheckOrigin(ctx) {
+		return fail(http.StatusForbidden, "origin not allowed")
+	}
+
+	subprotocol := selectSubprotocol(r.Header, opts.Subprotocols)
+	compress := opts.EnableCompression && offersPermessageDeflate(r.Header)
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return fail(http.StatusInternalServerError, "connection does not support hijacking")
+	}
+
+	// the server's read/write timeouts were meant for the HTTP exchange, not the socket
+	netConn.SetDeadline(time.Time{})
+
+	var response strings.Builder
+	response.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	response.WriteString("Upgrade: websocket\r\n")
+	response.WriteString("Connection: Upgrade\r\n")
+	response.WriteString("Sec-WebSocket-Accept: " + computeAcceptKey(key) + "\r\n")
+	if subprotocol != "" {
+		response.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	if compress {
+		response.WriteString("Sec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n")
+	}
+	response.WriteString("\r\n")
+
+	if _, err := brw.Writer.WriteString(response.String()); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if err := brw.Writer.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	ctx.Response.StatusCode = http.StatusSwitchingProtocols
+
+	conn := &WSConn{
+		Ctx:          ctx,
+		Subprotocol:  subprotocol,
+		conn:         netConn,
+		reader:       brw.Reader,
+		writer:       brw.Writer,
+		done:         make(chan struct{}),
+		compress:     compress,
+		readLimit:    opts.ReadLimit,
+		fragmentSize: opts.FragmentSize,
+		writeTimeout: opts.WriteTimeout,
+		pingInterval: opts.PingInterval,
+	}
+
+	if conn.pingInterval > 0 {
+		netConn.SetReadDeadline(time.Now().Add(2 * conn.pingInterval))
+		go conn.keepAlive()
+	}
+
+	return conn, nil
+}
+
+func (c *WSConn) GetParam(name string) string {
+	return c.Ctx.GetParam(name)
+}
+
+func (c *WSConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *WSConn) SetPongHandler(handler func(data string)) {
+	c.pongHandler = handler
+}
+
+// Done is closed once the underlying connection has been closed.
+func (c *WSConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// ReadMessage returns the next complete data message, reassembling fragments
+// and answering pings and close frames along the way.
+func (c *WSConn) ReadMessage() (int, []byte, error) {
+	messageType := 0
+	compressed := false
+	var message []byte
+
+	for {
+		frame, err := c.readFrame()
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+		if c.pingInterval > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(2 * c.pingInterval))
+		}
+
+		switch frame.opcode {
+		case WSPingMessage:
+			if err := c.writeControl(WSPongMessage, frame.payload); err != nil {
+				return 0, nil, c.fail(err)
+			}
+			continue
+		case WSPongMessage:
+			if c.pongHandler != nil {
+				c.pongHandler(string(frame.payload))
+			}
+			continue
+		case WSCloseMessage:
+			return 0, nil, c.handleClose(frame.payload)
+		case WSTextMessage, WSBinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(&WSCloseError{Code: WSCloseProtocolError, Reason: "expected continuation frame"})
+			}
+			messageType = frame.opcode
+			compressed = frame.rsv1
+			message = frame.payload
+		case WSContinuationMessage:
+			if messageType == 0 || frame.rsv1 {
+				return 0, nil, c.fail(&WSCloseError{Code: WSCloseProtocolError, Reason: "unexpected continuation frame"})
+			}
+			message = append(message, frame.payload...)
+		}
+
+		if int64(len(message)) > c.readLimit {
+			return 0, nil, c.fail(&WSCloseError{Code: WSCloseMessageTooBig, Reason: "message too big"})
+		}
+		if frame.fin {
+			break
+		}
+	}
+
+	if compressed {
+		inflated, err := c.inflate(message)
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+		message = inflated
+	}
+
+	if messageType == WSTextMessage && !utf8.Valid(message) {
+		return 0, nil, c.fail(&WSCloseError{Code: WSCloseInvalidPayload, Reason: "invalid UTF-8 in text message"})
+	}
+
+	return messageType, message, nil
+}
+
+func (c *WSConn) ReadJSON(v any) error {
+	_, message, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(message, v)
+}
+
+func (c *WSConn) WriteMessage(messageType int, data []byte) error {
+	if messageType != WSTextMessage && messageType != WSBinaryMessage {
+		return errors.New("websocket: WriteMessage only sends text or binary messages")
+	}
+
+	payload := data
+	compressed := false
+	if c.compress && len(data) >= wsCompressMinLength {
+		deflated, err := deflateMessage(data)
+		if err != nil {
+			return err
+		}
+		payload = deflated
+		compressed = true
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrWSClosed
+	}
+
+	if c.fragmentSize <= 0 || len(payload) <= c.fragmentSize {
+		return c.writeFrame(messageType, payload, true, compressed)
+	}
+
+	opcode := messageType
+	for len(payload) > 0 {
+		size := c.fragmentSize
+		if size > len(payload) {
+			size = len(payload)
+		}
+		last := size == len(payload)
+		if err := c.writeFrame(opcode, payload[:size], last, compressed && opcode != WSContinuationMessage); err != nil {
+			return err
+		}
+		payload = payload[size:]
+		opcode = WSContinuationMessage
+	}
+	return nil
+}
+
+func (c *WSConn) WriteText(text string) error {
+	return c.WriteMessage(WSTextMessage, []byte(text))
+}
+
+func (c *WSConn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(WSTextMessage, data)
+}
+
+func (c *WSConn) Ping(data []byte) error {
+	return c.writeControl(WSPingMessage, data)
+}
+
+func (c *WSConn) Close() error {
+	return c.CloseWithReason(WSCloseNormal, "")
+}
+
+// CloseWithReason starts the closing handshake. The TCP connection is dropped
+// once the peer answers (handled by ReadMessage) or after a grace period.
+func (c *WSConn) CloseWithReason(code int, reason string) error {
+	err := c.sendClose(code, reason)
+	time.AfterFunc(wsCloseGracePeriod, c.closeConn)
+	return err
+}
+
+func (c *WSConn) handleClose(payload []byte) error {
+	closeErr := &WSCloseError{Code: WSCloseNoStatus}
+
+	switch {
+	case len(payload) == 1:
+		closeErr = &WSCloseError{Code: WSCloseProtocolError, Reason: "invalid close payload"}
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !isValidCloseCode(closeErr.Code) {
+			closeErr = &WSCloseError{Code: WSCloseProtocolError, Reason: "invalid close code"}
+		} else if !utf8.ValidString(closeErr.Reason) {
+			closeErr = &WSCloseError{Code: WSCloseInvalidPayload, Reason: "invalid UTF-8 in close reason"}
+		}
+	}
+
+	if closeErr.Code == WSCloseNoStatus {
+		c.sendClose(0, "")
+	} else {
+		c.sendClose(closeErr.Code, "")
+	}
+	c.closeConn()
+	return closeErr
+}
+
+func (c *WSConn) sendClose(code int, reason string) error {
+	payload := []byte{}
+	if code != 0 {
+		if len(reason) > wsMaxControlPayload-2 {
+			reason = reason[:wsMaxControlPayload-2]
+		}
+		payload = make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+	return c.writeFrame(WSCloseMessage, payload, true, false)
+}
+
+// fail answers protocol violations with the matching close code and drops the connection.
+func (c *WSConn) fail(err error) error {
+	var closeErr *WSCloseError
+	if errors.As(err, &closeErr) {
+		c.sendClose(closeErr.Code, closeErr.Reason)
+	} else {
+		closeErr = &WSCloseError{Code: WSCloseAbnormal, Reason: err.Error()}
+	}
+	c.closeConn()
+	return closeErr
+}
+
+func (c *WSConn) closeConn() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *WSConn) keepAlive() {
+	ticker := time.NewTicker(c.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.Ping(nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *WSConn) writeControl(opcode int, payload []byte) error {
+	if len(payload) > wsMaxControlPayload {
+		return errors.New("websocket: control frame payload too large")
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrWSClosed
+	}
+	return c.writeFrame(opcode, payload, true, false)
+}
+
+// writeFrame must be called with writeMu held. Server frames are never masked.
+func (c *WSConn) writeFrame(opcode int, payload []byte, fin bool, rsv1 bool) error {
+	header := make([]byte, 0, 10)
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	if rsv1 {
+		b0 |= 0x40
+	}
+	header = append(header, b0)
+
+	switch length := len(payload); {
+	case length <= 125:
+		header = append(header, byte(length))
+	case length <= 0xffff:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	if c.writeTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	if _, err := c.writer.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.writer.Write(payload); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+type wsFrame struct {
+	fin     bool
+	rsv1    bool
+	opcode  int
+	payload []byte
+}
+
+func (c *WSConn) readFrame() (wsFrame, error) {
+	frame := wsFrame{}
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return frame, err
+	}
+
+	frame.fin = header[0]&0x80 != 0
+	frame.rsv1 = header[0]&0x40 != 0
+	frame.opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	if header[0]&0x30 != 0 {
+		return frame, &WSCloseError{Code: WSCloseProtocolError, Reason: "reserved bits set"}
+	}
+
+	isControl := frame.opcode >= WSCloseMessage
+	switch frame.opcode {
+	case WSContinuationMessage, WSTextMessage, WSBinaryMessage, WSCloseMessage, WSPingMessage, WSPongMessage:
+	default:
+		return frame, &WSCloseError{Code: WSCloseProtocolError, Reason: "unknown opcode"}
+	}
+	if frame.rsv1 && (isControl || !c.compress) {
+		return frame, &WSCloseError{Code: WSCloseProtocolError, Reason: "unexpected compressed frame"}
+	}
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return frame, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return frame, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+		if length>>63 != 0 {
+			return frame, &WSCloseError{Code: WSCloseProtocolError, Reason: "invalid frame length"}
+		}
+	}
+
+	if isControl && (length > wsMaxControlPayload || !frame.fin) {
+		return frame, &WSCloseError{Code: WSCloseProtocolError, Reason: "invalid control frame"}
+	}
+	if !masked {
+		return frame, &WSCloseError{Code: WSCloseProtocolError, Reason: "client frames must be masked"}
+	}
+	if length > uint64(c.readLimit) {
+		return frame, &WSCloseError{Code: WSCloseMessageTooBig, Reason: "message too big"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return frame, err
+	}
+
+	frame.payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, frame.payload); err != nil {
+		return frame, err
+	}
+	for i := range frame.payload {
+		frame.payload[i] ^= mask[i%4]
+	}
+
+	return frame, nil
+}
+
+// inflate decodes a permessage-deflate payload. Both sides negotiate no context
+// takeover, so every message is an independent deflate stream.
+func (c *WSConn) inflate(data []byte) ([]byte, error) {
+	reader := flate.NewReader(io.MultiReader(bytes.NewReader(data), strings.NewReader(wsDeflateFinalMarker)))
+	defer reader.Close()
+
+	inflated, err := io.ReadAll(io.LimitReader(reader, c.readLimit+1))
+	if err != nil {
+		return nil, &WSCloseError{Code: WSCloseInvalidPayload, Reason: "invalid compressed payload"}
+	}
+	if int64(len(inflated)) > c.readLimit {
+		return nil, &WSCloseError{Code: WSCloseMessageTooBig, Reason: "message too big"}
+	}
+	return inflated, nil
+}
+
+func deflateMessage(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte(wsDeflateTail)), nil
+}
+
+func computeAcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func isValidCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// isSameOrigin is the default origin check: browsers always send Origin, so a
+// cross-site page cannot open a socket unless CheckOrigin allows it.
+func isSameOrigin(ctx *Context) bool {
+	origin := ctx.Request.r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, ctx.Request.r.Host)
+}
+
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func selectSubprotocol(header http.Header, supported []string) string {
+	for _, value := range header.Values("Sec-WebSocket-Protocol") {
+		for _, offered := range strings.Split(value, ",") {
+			offered = strings.TrimSpace(offered)
+			for _, protocol := range supported {
+				if offered == protocol {
+					return protocol
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// offersPermessageDeflate reports whether the client offered permessage-deflate
+// with parameters we can honour; we always use a full 32KB window on our side.
+func offersPermessageDeflate(header http.Header) bool {
+	for _, value := range header.Values("Sec-WebSocket-Extensions") {
+		for _, extension := range strings.Split(value, ",") {
+			params := strings.Split(extension, ";")
+			if strings.TrimSpace(params[0]) != "permessage-deflate" {
+				continue
+			}
+			acceptable := true
+			for _, param := range params[1:] {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.TrimSpace(name) == "server_max_window_bits" && strings.Trim(strings.TrimSpace(value), `"`) != "15" {
+					acceptable = false
+				}
+			}
+			if acceptable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WSHub groups connections into rooms for broadcasting.
+type WSHub struct {
+	mu    sync.RWMutex
+	rooms map[string]map[*WSConn]struct{}
+}
+
+func NewWSHub() *WSHub {
+	return &WSHub{
+		rooms: make(map[string]map[*WSConn]struct{}),
+	}
+}
+
+func (h *WSHub) Join(room string, conn *WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[*WSConn]struct{})
+	}
+	h.rooms[room][conn] = struct{}{}
+}
+
+func (h *WSHub) Leave(room string, conn *WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.rooms[room], conn)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+func (h *WSHub) LeaveAll(conn *WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for room, members := range h.rooms {
+		delete(members, conn)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+func (h *WSHub) Members(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Broadcast sends a message to every member of room except the given connections.
+// Members that can no longer be written to are removed from every room.
+func (h *WSHub) Broadcast(room string, messageType int, data []byte, except ...*WSConn) {
+	h.mu.RLock()
+	members := make([]*WSConn, 0, len(h.rooms[room]))
+	for conn := range h.rooms[room] {
+		members = append(members, conn)
+	}
+	h.mu.RUnlock()
+
+	skip := make(map[*WSConn]struct{}, len(except))
+	for _, conn := range except {
+		skip[conn] = struct{}{}
+	}
+
+	for _, conn := range members {
+		if _, ok := skip[conn]; ok {
+			continue
+		}
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			h.LeaveAll(conn)
+		}
+	}
+}
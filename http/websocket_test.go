@@ -0,0 +1,304 @@
+package http_test
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ramansharma100/express-go/expresstest"
+	"github.com/ramansharma100/express-go/http"
+)
+
+// serveHijacking starts a real server for handlers that hijack the connection,
+// which httptest.Server.Close does not wait for. handlers.Wait returns once every
+// request, access log line included, is done.
+func serveHijacking(handler nethttp.Handler) (server *httptest.Server, handlers *sync.WaitGroup) {
+	handlers = &sync.WaitGroup{}
+	server = httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		handlers.Add(1)
+		defer handlers.Done()
+		handler.ServeHTTP(w, r)
+	}))
+	return server, handlers
+}
+
+type wsClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialWS(t *testing.T, server *httptest.Server, path string, header map[string]string) (*wsClient, *nethttp.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var request strings.Builder
+	request.WriteString("GET " + path + " HTTP/1.1\r\nHost: " + server.Listener.Addr().String() + "\r\n")
+	request.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	request.WriteString("Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n")
+	for key, value := range header {
+		request.WriteString(key + ": " + value + "\r\n")
+	}
+	request.WriteString("\r\n")
+	if _, err := io.WriteString(conn, request.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(conn)
+	res, err := nethttp.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &wsClient{conn: conn, reader: reader}, res
+}
+
+// write sends a masked frame, as clients must.
+func (c *wsClient) write(t *testing.T, opcode int, payload []byte, fin bool) {
+	t.Helper()
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, 0x80|byte(len(payload)))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	mask := make([]byte, 4)
+	rand.Read(mask)
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *wsClient) writeClose(t *testing.T, code int) {
+	t.Helper()
+	c.write(t, http.WSCloseMessage, binary.BigEndian.AppendUint16(nil, uint16(code)), true)
+}
+
+func (c *wsClient) read(t *testing.T) (int, []byte) {
+	t.Helper()
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		t.Fatal(err)
+	}
+	length := int(header[1] & 0x7f)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		io.ReadFull(c.reader, ext)
+		length = int(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		io.ReadFull(c.reader, ext)
+		length = int(binary.BigEndian.Uint64(ext))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	return int(header[0] & 0x0f), payload
+}
+
+func (c *wsClient) expectClose(t *testing.T, code int) {
+	t.Helper()
+	opcode, payload := c.read(t)
+	if opcode != http.WSCloseMessage || len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+		t.Errorf("expected close %d, got opcode %d %q", code, opcode, payload)
+	}
+}
+
+func TestWSEchoesMessages(t *testing.T) {
+	app := http.New()
+	app.SetServerOptions(&http.ServerOptions{WebSocket: &http.WSOptions{Subprotocols: []string{"chat"}}})
+	closed := make(chan error, 1)
+	app.WS("/echo/:room", func(conn *http.WSConn) {
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			conn.WriteMessage(messageType, []byte(conn.GetParam("room")+": "+string(message)))
+		}
+	})
+
+	output := captureStdout(t, func() {
+		server, handlers := serveHijacking(app)
+		defer server.Close()
+
+		client, res := dialWS(t, server, "/echo/lobby", map[string]string{"Sec-WebSocket-Protocol": "v2, chat"})
+		if res.StatusCode != 101 || res.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" || res.Header.Get("Sec-WebSocket-Protocol") != "chat" {
+			t.Fatalf("unexpected handshake %d %v", res.StatusCode, res.Header)
+		}
+
+		client.write(t, http.WSTextMessage, []byte("hi"), true)
+		if opcode, message := client.read(t); opcode != http.WSTextMessage || string(message) != "lobby: hi" {
+			t.Errorf("got opcode %d %q", opcode, message)
+		}
+
+		client.write(t, http.WSPingMessage, []byte("p"), true)
+		if opcode, message := client.read(t); opcode != http.WSPongMessage || string(message) != "p" {
+			t.Errorf("expected pong, got opcode %d %q", opcode, message)
+		}
+
+		client.write(t, http.WSBinaryMessage, []byte("frag"), false)
+		client.write(t, http.WSContinuationMessage, []byte("ments"), true)
+		if opcode, message := client.read(t); opcode != http.WSBinaryMessage || string(message) != "lobby: fragments" {
+			t.Errorf("got opcode %d %q", opcode, message)
+		}
+
+		client.writeClose(t, http.WSCloseGoingAway)
+		client.expectClose(t, http.WSCloseGoingAway)
+		if err := <-closed; fmt.Sprint(err) != "websocket: close 1001 " {
+			t.Errorf("handler got %v", err)
+		}
+		handlers.Wait()
+	})
+
+	if !strings.Contains(output, "GET /echo/lobby - 101") {
+		t.Errorf("expected the upgrade in the access log:\n%s", output)
+	}
+}
+
+func TestWSClosesOnProtocolErrors(t *testing.T) {
+	app := http.New()
+	app.WS("/", func(conn *http.WSConn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	captureStdout(t, func() {
+		server, handlers := serveHijacking(app)
+		defer server.Close()
+
+		client, _ := dialWS(t, server, "/", nil)
+		client.write(t, http.WSTextMessage, []byte{0xff, 0xfe}, true)
+		client.expectClose(t, http.WSCloseInvalidPayload)
+
+		client, _ = dialWS(t, server, "/", nil)
+		client.write(t, http.WSContinuationMessage, []byte("orphan"), true)
+		client.expectClose(t, http.WSCloseProtocolError)
+		handlers.Wait()
+	})
+}
+
+func TestWSRejectsBadHandshakes(t *testing.T) {
+	app := http.New()
+	app.Use(func(ctx *http.Context, next func()) {
+		if ctx.Request.GetHeader("Authorization") == "" {
+			ctx.Response.StatusCode = 401
+			ctx.Response.Writer.WriteHeader(401)
+			return
+		}
+		next()
+	})
+	app.WS("/ws", func(conn *http.WSConn) {})
+
+	upgrade := func(req *expresstest.Request) *expresstest.Request {
+		return req.Header("Authorization", "token").
+			Header("Connection", "Upgrade").
+			Header("Upgrade", "websocket").
+			Header("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==").
+			Header("Sec-WebSocket-Version", "13")
+	}
+
+	client := expresstest.New(t, app)
+	captureStdout(t, func() {
+		client.Get("/ws").Expect(401)
+		client.Get("/ws").Header("Authorization", "token").Expect(400).ExpectBody("missing websocket upgrade headers")
+		upgrade(client.Get("/ws")).Header("Sec-WebSocket-Version", "8").Expect(426).ExpectHeader("Sec-WebSocket-Version", "13")
+		upgrade(client.Get("/ws")).Header("Sec-WebSocket-Key", "short").Expect(400)
+		upgrade(client.Get("/ws")).Header("Origin", "http://evil.test").Expect(403).ExpectBody("origin not allowed")
+	})
+}
+
+func TestWSHubBroadcastsToRooms(t *testing.T) {
+	const clients = 5
+	hub := http.NewWSHub()
+	app := http.New()
+	app.WS("/rooms/:room", func(conn *http.WSConn) {
+		room := conn.GetParam("room")
+		hub.Join(room, conn)
+		defer hub.LeaveAll(conn)
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			hub.Broadcast(room, http.WSTextMessage, message, conn)
+		}
+	})
+
+	captureStdout(t, func() {
+		server, handlers := serveHijacking(app)
+		defer server.Close()
+
+		connections := make([]*wsClient, clients)
+		for i := range connections {
+			connections[i], _ = dialWS(t, server, "/rooms/general", nil)
+		}
+		other, _ := dialWS(t, server, "/rooms/other", nil)
+		deadline := time.Now().Add(time.Second)
+		for hub.Members("general") != clients || hub.Members("other") != 1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("clients did not join, general has %d", hub.Members("general"))
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		var wg sync.WaitGroup
+		received := make([][]string, clients)
+		for i, client := range connections {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				client.write(t, http.WSTextMessage, []byte(fmt.Sprint("from ", i)), true)
+				for len(received[i]) < clients-1 {
+					_, message := client.read(t)
+					received[i] = append(received[i], string(message))
+				}
+			}()
+		}
+		wg.Wait()
+
+		for i, messages := range received {
+			for _, message := range messages {
+				if message == fmt.Sprint("from ", i) {
+					t.Errorf("client %d received its own message", i)
+				}
+			}
+		}
+
+		for _, client := range append(connections, other) {
+			client.writeClose(t, http.WSCloseNormal)
+			client.expectClose(t, http.WSCloseNormal)
+		}
+		handlers.Wait()
+		if hub.Members("general") != 0 || hub.Members("other") != 0 {
+			t.Errorf("connections were not removed from the rooms")
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// responseWriter tracks what has been sent so middlewares can tell
+// whether it is still safe to change the status or headers.
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int64
+	written bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+	w.status = code
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		conn, rw, err := h.Hijack()
+		if err == nil {
+			// the connection belongs to the caller now, nothing else may write a response
+			w.written = true
+			w.status = http.StatusSwitchingProtocols
+		}
+		return conn, rw, err
+	}
+	return nil, nil, errors.New("http.Hijacker is not supported by the underlying ResponseWriter")
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) Written() bool {
+	return w.written
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int64 {
+	return w.size
+}
+
+// wrappedWriter is embedded by the writers middlewares put in place of
+// ctx.Response.Writer to buffer or transform the response. committed is set
+// once the response goes to the underlying writer, or the connection is hijacked.
+type wrappedWriter struct {
+	http.ResponseWriter
+	committed bool
+}
+
+func (w *wrappedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		conn, rw, err := h.Hijack()
+		if err == nil {
+			w.committed = true
+		}
+		return conn, rw, err
+	}
+	return nil, nil, errors.New("http.Hijacker is not supported by the underlying ResponseWriter")
+}
+
+func (w *wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *wrappedWriter) Written() bool {
+	if w.committed {
+		return true
+	}
+	if written, ok := w.ResponseWriter.(interface{ Written() bool }); ok {
+		return written.Written()
+	}
+	return false
+}
+
+// withWriter runs next with ctx.Response.Writer replaced by w and puts the
+// original writer back afterwards. When the chain panics, onPanic runs before
+// the panic is passed on as a *PanicError.
+func withWriter(ctx *Context, w http.ResponseWriter, next func(), onPanic func()) {
+	original := ctx.Response.Writer
+	ctx.Response.Writer = w
+
+	defer func() {
+		ctx.Response.Writer = original
+		if recovered := recover(); recovered != nil {
+			err := wrapPanic(recovered)
+			if onPanic != nil {
+				onPanic()
+			}
+			panic(err)
+		}
+	}()
+
+	next()
+}